@@ -6,19 +6,22 @@ import (
 	"fmt"
 	"log"
 	"os" // Added for environment variables
+	"regexp"
 	// "time" // Removed as it's not currently used in this file
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"quake_log_parser/rating"
 	"quake_log_parser/reporter" // Ensure this import is present
 	// We will need "quake_log_parser/reporter" for GameReport type in StoreGameReports
 )
 
 const (
-	DefaultMongoDBURI         = "mongodb://localhost:27017"
-	defaultDatabaseName       = "quake_reports_db" // Moved from main.go and made unexported
+	DefaultMongoDBURI            = "mongodb://localhost:27017"
+	defaultDatabaseName          = "quake_reports_db" // Moved from main.go and made unexported
 	defaultGameReportsCollection = "game_reports"     // Moved from main.go and made unexported
+	defaultRatingsCollection     = "player_ratings"
 )
 
 // ConnectDB establishes a connection to MongoDB and returns the client.
@@ -56,27 +59,36 @@ func GetGameReportsCollection(client *mongo.Client) *mongo.Collection {
 	return client.Database(defaultDatabaseName).Collection(defaultGameReportsCollection)
 }
 
-// StoreGameReports takes a map of game reports and stores them in the specified MongoDB collection.
-// Each key-value pair in the reports map is intended to be a separate document.
-// The key (gameID as int) will be used as the _id field in MongoDB for idempotency.
-func StoreGameReports(ctx context.Context, collection *mongo.Collection, reports map[int]interface{}) error {
+// GetRatingsCollection returns the collection holding player ratings, in the same database as gameCollection.
+func GetRatingsCollection(gameCollection *mongo.Collection) *mongo.Collection {
+	return gameCollection.Database().Collection(defaultRatingsCollection)
+}
+
+// StoreGameReports stores the reports whose ID (_id) is not in the collection yet.
+// Reports already stored are left untouched, so storing the same log twice changes nothing.
+// It returns the IDs of the reports it added, in the order given.
+func StoreGameReports(ctx context.Context, collection *mongo.Collection, reports []reporter.GameReport) ([]string, error) {
 	if collection == nil {
-		return fmt.Errorf("MongoDB collection is nil")
+		return nil, fmt.Errorf("MongoDB collection is nil")
 	}
 	if len(reports) == 0 {
 		fmt.Println("No reports to store in MongoDB.")
-		return nil
+		return []string{}, nil
 	}
 
 	var operations []mongo.WriteModel
 
-	for gameID, reportData := range reports {
-		// We'll use gameID (int) as the _id.
-		// Using UpdateOne with Upsert ensures that if we run this multiple times,
-		// existing game reports are updated, and new ones are inserted.
-		filter := bson.M{"_id": gameID}
-		update := bson.M{"$set": reportData} // reportData should be the GameReport struct
-		
+	for _, report := range reports {
+		reportData, err := toDocument(report)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode game report %s: %w", report.ID, err)
+		}
+		delete(reportData, "_id") // Set by the filter on insert, and never changed
+
+		// $setOnInsert only writes when the upsert inserts, which keeps the first copy of a game
+		filter := bson.M{"_id": report.ID}
+		update := bson.M{"$setOnInsert": reportData}
+
 		model := mongo.NewUpdateOneModel().
 			SetFilter(filter).
 			SetUpdate(update).
@@ -84,33 +96,103 @@ func StoreGameReports(ctx context.Context, collection *mongo.Collection, reports
 		operations = append(operations, model)
 	}
 
-	if len(operations) > 0 {
-		bulkWriteOptions := options.BulkWrite().SetOrdered(false) // Unordered for potentially better performance
-		result, err := collection.BulkWrite(ctx, operations, bulkWriteOptions)
-		if err != nil {
-			return fmt.Errorf("failed to bulk write game reports to MongoDB: %w", err)
+	bulkWriteOptions := options.BulkWrite().SetOrdered(false) // Unordered for potentially better performance
+	result, err := collection.BulkWrite(ctx, operations, bulkWriteOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to bulk write game reports to MongoDB: %w", err)
+	}
+	fmt.Printf("MongoDB BulkWrite: Upserted %d of %d documents.\n", result.UpsertedCount, len(reports))
+
+	stored := make([]string, 0, len(result.UpsertedIDs))
+	for i, report := range reports {
+		if _, ok := result.UpsertedIDs[int64(i)]; ok {
+			stored = append(stored, report.ID)
+		}
+	}
+	return stored, nil
+}
+
+// toDocument marshals v into a bson.M using its bson tags.
+func toDocument(v interface{}) (bson.M, error) {
+	data, err := bson.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var document bson.M
+	err = bson.Unmarshal(data, &document)
+	return document, err
+}
+
+// gameOrder sorts game reports in the order they were stored: by upload time, then log and position in the log.
+var gameOrder = bson.D{{Key: "uploaded_at", Value: 1}, {Key: "source", Value: 1}, {Key: "ordinal", Value: 1}, {Key: "_id", Value: 1}}
+
+// MigrateNumericGameIDs rewrites the games stored before IDs were hashes, whose _id is their position in the log,
+// to use that number as a string ID and as their ordinal. It returns how many games it rewrote.
+func MigrateNumericGameIDs(ctx context.Context, collection *mongo.Collection) (int, error) {
+	if collection == nil {
+		return 0, fmt.Errorf("MongoDB collection is nil")
+	}
+
+	cursor, err := collection.Find(ctx, bson.M{"_id": bson.M{"$type": "number"}})
+	if err != nil {
+		return 0, fmt.Errorf("failed to find games with numeric IDs: %w", err)
+	}
+	var documents []bson.M
+	if err := cursor.All(ctx, &documents); err != nil {
+		return 0, fmt.Errorf("failed to decode games with numeric IDs: %w", err)
+	}
+
+	// _id cannot be updated, so each game is copied under its new ID and the old document removed
+	for _, document := range documents {
+		oldID := document["_id"]
+		document["_id"] = fmt.Sprint(oldID)
+		if _, ok := document["ordinal"]; !ok {
+			document["ordinal"] = oldID
+		}
+		if _, err := collection.InsertOne(ctx, document); err != nil && !mongo.IsDuplicateKeyError(err) {
+			return 0, fmt.Errorf("failed to copy game %v: %w", oldID, err)
 		}
-		fmt.Printf("MongoDB BulkWrite: Inserted %d, Updated %d, Upserted %d documents.\n", result.InsertedCount, result.ModifiedCount, result.UpsertedCount)
-	} else {
-		fmt.Println("No operations to perform for MongoDB storage.")
+		if _, err := collection.DeleteOne(ctx, bson.M{"_id": oldID}); err != nil {
+			return 0, fmt.Errorf("failed to remove game %v: %w", oldID, err)
+		}
+	}
+	return len(documents), nil
+}
+
+// EnsureIndexes creates the indexes behind the upload and duplicate lookups: hash and upload_id on the games,
+// sha256 on the uploads collection next to them. Indexes that already exist are left as they are.
+func EnsureIndexes(ctx context.Context, collection *mongo.Collection) error {
+	if collection == nil {
+		return fmt.Errorf("MongoDB collection is nil")
 	}
 
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "hash", Value: 1}}},
+		{Keys: bson.D{{Key: "upload_id", Value: 1}}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create game indexes: %w", err)
+	}
+	_, err = GetUploadsCollection(collection).Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: "sha256", Value: 1}}})
+	if err != nil {
+		return fmt.Errorf("failed to create upload indexes: %w", err)
+	}
 	return nil
 }
 
-// PrintAllStoredGames retrieves all documents from the given collection, sorted by _id, and prints them as JSON.
+// PrintAllStoredGames retrieves all documents from the given collection, in the order they were stored, and prints them as JSON.
 func PrintAllStoredGames(ctx context.Context, collection *mongo.Collection) error {
 	if collection == nil {
 		return fmt.Errorf("MongoDB collection is nil")
 	}
 
 	// Use internal constant for collection name in log message, or keep collection.Name()
-	fmt.Printf("\n--- All Stored Game Reports from MongoDB collection '%s' (Sorted by Game ID) ---\n", defaultGameReportsCollection)
+	fmt.Printf("\n--- All Stored Game Reports from MongoDB collection '%s' (In Stored Order) ---\n", defaultGameReportsCollection)
 
 	findOptions := options.Find()
-	findOptions.SetSort(bson.D{{"_id", 1}}) // Sort by _id in ascending order
+	findOptions.SetSort(gameOrder)
 
-	cursor, err := collection.Find(ctx, bson.D{{}}, findOptions) 
+	cursor, err := collection.Find(ctx, bson.D{{}}, findOptions)
 	if err != nil {
 		return fmt.Errorf("failed to find documents in MongoDB: %w", err)
 	}
@@ -121,7 +203,7 @@ func PrintAllStoredGames(ctx context.Context, collection *mongo.Collection) erro
 		foundAny = true
 		var result bson.M
 		if err := cursor.Decode(&result); err != nil {
-			log.Printf("Error decoding document from MongoDB: %v", err) 
+			log.Printf("Error decoding document from MongoDB: %v", err)
 			continue
 		}
 
@@ -144,16 +226,56 @@ func PrintAllStoredGames(ctx context.Context, collection *mongo.Collection) erro
 	return nil
 }
 
-// GetAllGameReports retrieves all game reports from the collection, sorted by game ID (_id).
+// GameFilter narrows down the game reports returned by FindGameReports.
+// Zero values mean "do not filter on this field".
+type GameFilter struct {
+	ExitReason  string // Case-insensitive substring of the exit reason, e.g. "fraglimit"
+	Aborted     *bool  // Only games that ended without (true) or with (false) an Exit line
+	MinDuration int    // Minimum game duration in seconds
+	MaxDuration int    // Maximum game duration in seconds
+	UploadID    string // Only games first stored by this upload
+}
+
+// toBSON converts the filter into a MongoDB query document.
+func (f GameFilter) toBSON() bson.M {
+	query := bson.M{}
+	if f.ExitReason != "" {
+		query["exit_reason"] = bson.M{"$regex": regexp.QuoteMeta(f.ExitReason), "$options": "i"}
+	}
+	if f.Aborted != nil {
+		query["aborted"] = *f.Aborted
+	}
+	duration := bson.M{}
+	if f.MinDuration > 0 {
+		duration["$gte"] = f.MinDuration
+	}
+	if f.MaxDuration > 0 {
+		duration["$lte"] = f.MaxDuration
+	}
+	if len(duration) > 0 {
+		query["duration_seconds"] = duration
+	}
+	if f.UploadID != "" {
+		query["upload_id"] = f.UploadID
+	}
+	return query
+}
+
+// GetAllGameReports retrieves all game reports from the collection, in the order they were stored.
 func GetAllGameReports(ctx context.Context, collection *mongo.Collection) ([]reporter.GameReport, error) {
+	return FindGameReports(ctx, collection, GameFilter{})
+}
+
+// FindGameReports retrieves the game reports matching filter, in the order they were stored.
+func FindGameReports(ctx context.Context, collection *mongo.Collection, filter GameFilter) ([]reporter.GameReport, error) {
 	if collection == nil {
 		return nil, fmt.Errorf("MongoDB collection is nil")
 	}
 
 	findOptions := options.Find()
-	findOptions.SetSort(bson.D{{"_id", 1}}) // Sort by _id (gameID) in ascending order
+	findOptions.SetSort(gameOrder)
 
-	cursor, err := collection.Find(ctx, bson.D{{}}, findOptions)
+	cursor, err := collection.Find(ctx, filter.toBSON(), findOptions)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find documents in MongoDB: %w", err)
 	}
@@ -173,9 +295,44 @@ func GetAllGameReports(ctx context.Context, collection *mongo.Collection) ([]rep
 	return reports, nil
 }
 
+// FindGameIDsByHash maps each of hashes that a stored game has to the ID of the first such game stored.
+func FindGameIDsByHash(ctx context.Context, collection *mongo.Collection, hashes []string) (map[string]string, error) {
+	if collection == nil {
+		return nil, fmt.Errorf("MongoDB collection is nil")
+	}
+	found := make(map[string]string)
+	if len(hashes) == 0 {
+		return found, nil
+	}
+
+	findOptions := options.Find().SetSort(gameOrder).SetProjection(bson.M{"_id": 1, "hash": 1})
+	cursor, err := collection.Find(ctx, bson.M{"hash": bson.M{"$in": hashes}}, findOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find games by hash: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var game struct {
+			ID   string `bson:"_id"`
+			Hash string `bson:"hash"`
+		}
+		if err := cursor.Decode(&game); err != nil {
+			return nil, fmt.Errorf("failed to decode game: %w", err)
+		}
+		if _, ok := found[game.Hash]; !ok {
+			found[game.Hash] = game.ID
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("failed to find games by hash: %w", err)
+	}
+	return found, nil
+}
+
 // DeleteGameReportByID deletes a single game report by its ID from MongoDB.
 // It returns the number of documents deleted (0 or 1) and an error if any occurs.
-func DeleteGameReportByID(ctx context.Context, collection *mongo.Collection, gameID int) (int64, error) {
+func DeleteGameReportByID(ctx context.Context, collection *mongo.Collection, gameID string) (int64, error) {
 	if collection == nil {
 		return 0, fmt.Errorf("MongoDB collection is nil")
 	}
@@ -183,7 +340,7 @@ func DeleteGameReportByID(ctx context.Context, collection *mongo.Collection, gam
 	filter := bson.M{"_id": gameID}
 	result, err := collection.DeleteOne(ctx, filter)
 	if err != nil {
-		return 0, fmt.Errorf("failed to delete game report with ID %d: %w", gameID, err)
+		return 0, fmt.Errorf("failed to delete game report with ID %s: %w", gameID, err)
 	}
 
 	return result.DeletedCount, nil
@@ -212,7 +369,7 @@ func DeleteAllGameReportsFromDB(ctx context.Context, collection *mongo.Collectio
 // It returns the report and nil on success.
 // It returns (nil, nil) if the document is not found.
 // It returns (nil, error) for other errors.
-func GetGameReportByID(ctx context.Context, collection *mongo.Collection, gameID int) (*reporter.GameReport, error) {
+func GetGameReportByID(ctx context.Context, collection *mongo.Collection, gameID string) (*reporter.GameReport, error) {
 	if collection == nil {
 		return nil, fmt.Errorf("MongoDB collection is nil")
 	}
@@ -225,13 +382,73 @@ func GetGameReportByID(ctx context.Context, collection *mongo.Collection, gameID
 		if err == mongo.ErrNoDocuments {
 			return nil, nil // Not found
 		}
-		return nil, fmt.Errorf("failed to find or decode game report with ID %d: %w", gameID, err)
+		return nil, fmt.Errorf("failed to find or decode game report with ID %s: %w", gameID, err)
 	}
 	return &report, nil
 }
 
+// ReplaceRatings replaces every stored player rating with ratings.
+func ReplaceRatings(ctx context.Context, collection *mongo.Collection, ratings []rating.PlayerRating) error {
+	if collection == nil {
+		return fmt.Errorf("MongoDB collection is nil")
+	}
+
+	if _, err := collection.DeleteMany(ctx, bson.D{}); err != nil {
+		return fmt.Errorf("failed to clear player ratings: %w", err)
+	}
+	if len(ratings) == 0 {
+		return nil
+	}
+	documents := make([]interface{}, 0, len(ratings))
+	for _, r := range ratings {
+		documents = append(documents, r)
+	}
+	if _, err := collection.InsertMany(ctx, documents); err != nil {
+		return fmt.Errorf("failed to store player ratings: %w", err)
+	}
+	return nil
+}
+
+// GetAllRatings retrieves every stored player rating, best first.
+func GetAllRatings(ctx context.Context, collection *mongo.Collection) ([]rating.PlayerRating, error) {
+	if collection == nil {
+		return nil, fmt.Errorf("MongoDB collection is nil")
+	}
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "rating", Value: -1}, {Key: "_id", Value: 1}})
+	cursor, err := collection.Find(ctx, bson.D{}, findOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find player ratings: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	ratings := []rating.PlayerRating{}
+	if err = cursor.All(ctx, &ratings); err != nil {
+		return nil, fmt.Errorf("failed to decode player ratings: %w", err)
+	}
+	return ratings, nil
+}
+
+// GetRatingByPlayer retrieves the stored rating of a player.
+// It returns (nil, nil) if the player has no rating.
+func GetRatingByPlayer(ctx context.Context, collection *mongo.Collection, name string) (*rating.PlayerRating, error) {
+	if collection == nil {
+		return nil, fmt.Errorf("MongoDB collection is nil")
+	}
+
+	var playerRating rating.PlayerRating
+	err := collection.FindOne(ctx, bson.M{"_id": name}).Decode(&playerRating)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil // Not found
+		}
+		return nil, fmt.Errorf("failed to find or decode rating of player %s: %w", name, err)
+	}
+	return &playerRating, nil
+}
+
 // Note: The StoreGameReports function expects 'reports' to be map[string]interface{}
 // for flexibility with BSON marshalling, but the values should ideally be structured
 // (like reporter.GameReport). The BSON tags on the GameReport struct will guide marshalling.
 // We also need to import "go.mongodb.org/mongo-driver/bson" in this file for bson.M
-// and ensure that the reporter.GameReport struct is accessible if we were to type `reports` more strictly. 
\ No newline at end of file
+// and ensure that the reporter.GameReport struct is accessible if we were to type `reports` more strictly.
@@ -0,0 +1,236 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"quake_log_parser/rating"
+	"quake_log_parser/reporter"
+)
+
+// MemoryStore is a GameStore that keeps everything in memory. It is safe for concurrent use.
+type MemoryStore struct {
+	mu      sync.RWMutex
+	games   map[string]reporter.GameReport
+	ratings map[string]rating.PlayerRating
+	uploads map[string]Upload
+}
+
+// NewMemoryStore returns an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		games:   make(map[string]reporter.GameReport),
+		ratings: make(map[string]rating.PlayerRating),
+		uploads: make(map[string]Upload),
+	}
+}
+
+func (s *MemoryStore) StoreGameReports(ctx context.Context, reports []reporter.GameReport) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := []string{}
+	for _, report := range reports {
+		if _, ok := s.games[report.ID]; ok {
+			continue // Already stored
+		}
+		s.games[report.ID] = report
+		stored = append(stored, report.ID)
+	}
+	return stored, nil
+}
+
+func (s *MemoryStore) GetGameReport(ctx context.Context, gameID string) (*reporter.GameReport, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	report, ok := s.games[gameID]
+	if !ok {
+		return nil, nil // Not found
+	}
+	return &report, nil
+}
+
+func (s *MemoryStore) FindGameReports(ctx context.Context, filter GameFilter) ([]reporter.GameReport, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	reports := []reporter.GameReport{}
+	for _, report := range s.games {
+		if filter.matches(report) {
+			reports = append(reports, report)
+		}
+	}
+	sortGameReports(reports)
+	return reports, nil
+}
+
+func (s *MemoryStore) FindGameIDsByHash(ctx context.Context, hashes []string) (map[string]string, error) {
+	wanted := make(map[string]bool, len(hashes))
+	for _, hash := range hashes {
+		wanted[hash] = true
+	}
+	reports, err := s.FindGameReports(ctx, GameFilter{})
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[string]string)
+	for _, report := range reports {
+		if _, ok := found[report.Hash]; !ok && wanted[report.Hash] {
+			found[report.Hash] = report.ID
+		}
+	}
+	return found, nil
+}
+
+func (s *MemoryStore) DeleteGameReport(ctx context.Context, gameID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.games[gameID]
+	delete(s.games, gameID)
+	return ok, nil
+}
+
+func (s *MemoryStore) DeleteAllGameReports(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.games = make(map[string]reporter.GameReport)
+	return nil
+}
+
+func (s *MemoryStore) RankPlayers(ctx context.Context, opts reporter.RankingOptions) ([]reporter.PlayerRankEntry, error) {
+	reports, err := s.FindGameReports(ctx, GameFilter{})
+	if err != nil {
+		return nil, err
+	}
+	return reporter.RankPlayers(reports, opts), nil
+}
+
+func (s *MemoryStore) GetKillMatrix(ctx context.Context, gameID string) (map[string]map[string]int, error) {
+	report, err := s.GetGameReport(ctx, gameID)
+	if err != nil || report == nil {
+		return nil, err
+	}
+	return killMatrix(*report), nil
+}
+
+func (s *MemoryStore) ReplaceRatings(ctx context.Context, ratings []rating.PlayerRating) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ratings = make(map[string]rating.PlayerRating, len(ratings))
+	for _, r := range ratings {
+		s.ratings[r.Name] = r
+	}
+	return nil
+}
+
+func (s *MemoryStore) GetRatings(ctx context.Context) ([]rating.PlayerRating, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ratings := make([]rating.PlayerRating, 0, len(s.ratings))
+	for _, r := range s.ratings {
+		ratings = append(ratings, r)
+	}
+	sort.Slice(ratings, func(i, j int) bool {
+		if ratings[i].Rating != ratings[j].Rating {
+			return ratings[i].Rating > ratings[j].Rating
+		}
+		return ratings[i].Name < ratings[j].Name
+	})
+	return ratings, nil
+}
+
+func (s *MemoryStore) GetRating(ctx context.Context, name string) (*rating.PlayerRating, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	r, ok := s.ratings[name]
+	if !ok {
+		return nil, nil // Not found
+	}
+	return &r, nil
+}
+
+func (s *MemoryStore) StoreUpload(ctx context.Context, upload Upload) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.uploads[upload.ID]; ok {
+		return fmt.Errorf("upload %s already exists", upload.ID)
+	}
+	s.uploads[upload.ID] = upload
+	return nil
+}
+
+func (s *MemoryStore) GetUpload(ctx context.Context, uploadID string) (*Upload, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	upload, ok := s.uploads[uploadID]
+	if !ok {
+		return nil, nil // Not found
+	}
+	return &upload, nil
+}
+
+func (s *MemoryStore) ListUploads(ctx context.Context) ([]Upload, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	uploads := make([]Upload, 0, len(s.uploads))
+	for _, upload := range s.uploads {
+		uploads = append(uploads, upload)
+	}
+	sort.Slice(uploads, func(i, j int) bool {
+		if !uploads[i].UploadedAt.Equal(uploads[j].UploadedAt) {
+			return uploads[i].UploadedAt.After(uploads[j].UploadedAt)
+		}
+		return uploads[i].ID < uploads[j].ID
+	})
+	return uploads, nil
+}
+
+func (s *MemoryStore) FindUploadsBySHA256(ctx context.Context, sha256 string) ([]Upload, error) {
+	uploads, err := s.ListUploads(ctx)
+	if err != nil {
+		return nil, err
+	}
+	matching := []Upload{}
+	for _, upload := range uploads {
+		if upload.SHA256 == sha256 {
+			matching = append(matching, upload)
+		}
+	}
+	return matching, nil
+}
+
+func (s *MemoryStore) DeleteUpload(ctx context.Context, uploadID string) (bool, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	deleted := 0
+	for id, report := range s.games {
+		if report.UploadID == uploadID {
+			delete(s.games, id)
+			deleted++
+		}
+	}
+	_, ok := s.uploads[uploadID]
+	delete(s.uploads, uploadID)
+	return ok, deleted, nil
+}
+
+// matches applies the filter to a report the way toBSON does in MongoDB.
+func (f GameFilter) matches(report reporter.GameReport) bool {
+	if f.ExitReason != "" && !strings.Contains(strings.ToLower(report.ExitReason), strings.ToLower(f.ExitReason)) {
+		return false
+	}
+	if f.Aborted != nil && report.Aborted != *f.Aborted {
+		return false
+	}
+	if f.MinDuration > 0 && report.DurationSeconds < f.MinDuration {
+		return false
+	}
+	if f.MaxDuration > 0 && report.DurationSeconds > f.MaxDuration {
+		return false
+	}
+	if f.UploadID != "" && report.UploadID != f.UploadID {
+		return false
+	}
+	return true
+}
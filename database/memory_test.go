@@ -0,0 +1,192 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"quake_log_parser/rating"
+	"quake_log_parser/reporter"
+)
+
+func TestMemoryStore_FindGameReports(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	aborted := true
+	first := time.Date(2026, 1, 2, 15, 0, 0, 0, time.UTC)
+	_, err := store.StoreGameReports(ctx, []reporter.GameReport{
+		{ID: "c", Ordinal: 1, UploadedAt: first.Add(time.Hour), ExitReason: "Timelimit hit.", DurationSeconds: 900},
+		{ID: "b", Ordinal: 2, UploadedAt: first, Aborted: true, DurationSeconds: 30},
+		{ID: "a", Ordinal: 1, UploadedAt: first, ExitReason: "Fraglimit hit.", DurationSeconds: 120},
+	})
+	if err != nil {
+		t.Fatalf("StoreGameReports returned an error: %v", err)
+	}
+
+	// Stored order: by upload time, then position in the log
+	all, _ := store.FindGameReports(ctx, GameFilter{})
+	if len(all) != 3 || all[0].ID != "a" || all[1].ID != "b" || all[2].ID != "c" {
+		t.Fatalf("Expected games a, b, c in order, got %+v", all)
+	}
+
+	tests := []struct {
+		name   string
+		filter GameFilter
+		want   []string
+	}{
+		{"exit reason", GameFilter{ExitReason: "FRAG"}, []string{"a"}},
+		{"aborted", GameFilter{Aborted: &aborted}, []string{"b"}},
+		{"duration range", GameFilter{MinDuration: 60, MaxDuration: 600}, []string{"a"}},
+		{"no match", GameFilter{ExitReason: "capturelimit"}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reports, err := store.FindGameReports(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("FindGameReports returned an error: %v", err)
+			}
+			if reports == nil {
+				t.Fatal("Expected an empty slice, got nil")
+			}
+			if len(reports) != len(tt.want) {
+				t.Fatalf("Expected games %v, got %+v", tt.want, reports)
+			}
+			for i, id := range tt.want {
+				if reports[i].ID != id {
+					t.Errorf("Expected game %s at %d, got %s", id, i, reports[i].ID)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryStore_GetAndDelete(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	stored, _ := store.StoreGameReports(ctx, []reporter.GameReport{{ID: "7", Map: "q3dm17"}})
+	if len(stored) != 1 || stored[0] != "7" {
+		t.Fatalf("Expected game 7 to be stored, got %v", stored)
+	}
+
+	// Storing the same ID again keeps the first copy
+	stored, _ = store.StoreGameReports(ctx, []reporter.GameReport{{ID: "7", Map: "q3dm6"}, {ID: "8", Map: "q3dm6"}})
+	if len(stored) != 1 || stored[0] != "8" {
+		t.Errorf("Expected only game 8 to be stored, got %v", stored)
+	}
+
+	report, err := store.GetGameReport(ctx, "7")
+	if err != nil || report == nil || report.Map != "q3dm17" {
+		t.Fatalf("Expected game 7 on q3dm17, got %+v (err %v)", report, err)
+	}
+	if report, _ := store.GetGameReport(ctx, "9"); report != nil {
+		t.Errorf("Expected no game 9, got %+v", report)
+	}
+
+	if deleted, _ := store.DeleteGameReport(ctx, "7"); !deleted {
+		t.Error("Expected game 7 to be deleted")
+	}
+	if deleted, _ := store.DeleteGameReport(ctx, "7"); deleted {
+		t.Error("Expected a second delete of game 7 to find nothing")
+	}
+}
+
+func TestMemoryStore_Ratings(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	store.ReplaceRatings(ctx, []rating.PlayerRating{{Name: "b", Rating: 1500}, {Name: "a", Rating: 1500}, {Name: "c", Rating: 1600}})
+
+	ratings, _ := store.GetRatings(ctx)
+	if len(ratings) != 3 || ratings[0].Name != "c" || ratings[1].Name != "a" || ratings[2].Name != "b" {
+		t.Fatalf("Expected c, a, b, got %+v", ratings)
+	}
+
+	store.ReplaceRatings(ctx, []rating.PlayerRating{{Name: "a", Rating: 1510}})
+	if r, _ := store.GetRating(ctx, "c"); r != nil {
+		t.Errorf("Expected ReplaceRatings to drop c, got %+v", r)
+	}
+	if r, _ := store.GetRating(ctx, "a"); r == nil || r.Rating != 1510 {
+		t.Errorf("Expected a at 1510, got %+v", r)
+	}
+}
+
+func TestMemoryStore_Uploads(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	first := time.Date(2026, 1, 2, 15, 0, 0, 0, time.UTC)
+	store.StoreUpload(ctx, Upload{ID: "u1", UploadedAt: first, GameIDs: []string{"a", "b"}})
+	store.StoreUpload(ctx, Upload{ID: "u2", UploadedAt: first.Add(time.Hour), GameIDs: []string{"b", "c"}})
+	if err := store.StoreUpload(ctx, Upload{ID: "u1"}); err == nil {
+		t.Error("Expected storing upload u1 twice to fail")
+	}
+	// u2 found game b already stored by u1
+	store.StoreGameReports(ctx, []reporter.GameReport{{ID: "a", UploadID: "u1"}, {ID: "b", UploadID: "u1"}})
+	store.StoreGameReports(ctx, []reporter.GameReport{{ID: "b", UploadID: "u2"}, {ID: "c", UploadID: "u2"}})
+
+	uploads, _ := store.ListUploads(ctx)
+	if len(uploads) != 2 || uploads[0].ID != "u2" || uploads[1].ID != "u1" {
+		t.Fatalf("Expected uploads u2, u1, got %+v", uploads)
+	}
+	if owned, _ := store.FindGameReports(ctx, GameFilter{UploadID: "u2"}); len(owned) != 1 || owned[0].ID != "c" {
+		t.Errorf("Expected upload u2 to own game c only, got %+v", owned)
+	}
+
+	found, deleted, err := store.DeleteUpload(ctx, "u2")
+	if err != nil || !found || deleted != 1 {
+		t.Fatalf("Expected upload u2 and 1 game to be deleted, got %v, %d (err %v)", found, deleted, err)
+	}
+	if report, _ := store.GetGameReport(ctx, "b"); report == nil {
+		t.Error("Expected game b, stored by u1, to survive")
+	}
+	if upload, _ := store.GetUpload(ctx, "u2"); upload != nil {
+		t.Errorf("Expected no upload u2, got %+v", upload)
+	}
+	if found, _, _ := store.DeleteUpload(ctx, "u2"); found {
+		t.Error("Expected a second delete of upload u2 to find nothing")
+	}
+}
+
+func TestStoreNewGameReports(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	store.StoreGameReports(ctx, []reporter.GameReport{{ID: "a", Hash: "h1"}, {ID: "legacy"}})
+
+	outcomes, err := StoreNewGameReports(ctx, store, []reporter.GameReport{
+		{ID: "b", Ordinal: 1, Hash: "h1"}, // Same lines as a, from another source
+		{ID: "c", Ordinal: 2, Hash: "h2"},
+		{ID: "d", Ordinal: 3, Hash: "h2"}, // Same lines as c, earlier in the file
+		{ID: "legacy", Ordinal: 4, Hash: "h3"},
+	})
+	if err != nil {
+		t.Fatalf("StoreNewGameReports returned an error: %v", err)
+	}
+	want := []GameOutcome{
+		{ID: "b", Ordinal: 1, Status: GameDuplicate, DuplicateOf: "a"},
+		{ID: "c", Ordinal: 2, Status: GameNew},
+		{ID: "d", Ordinal: 3, Status: GameDuplicate, DuplicateOf: "c"},
+		{ID: "legacy", Ordinal: 4, Status: GameDuplicate, DuplicateOf: "legacy"},
+	}
+	for i := range want {
+		if outcomes[i] != want[i] {
+			t.Errorf("Expected %+v, got %+v", want[i], outcomes[i])
+		}
+	}
+	if all, _ := store.FindGameReports(ctx, GameFilter{}); len(all) != 3 {
+		t.Errorf("Expected only game c to be added, got %+v", all)
+	}
+}
+
+func TestSkipStoredGameReports(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	store.StoreGameReports(ctx, []reporter.GameReport{{ID: "a", Hash: "h1"}, {ID: "b", Hash: "h2"}})
+
+	outcomes, err := SkipStoredGameReports(ctx, store, []reporter.GameReport{{ID: "a", Ordinal: 1, Hash: "h1"}, {ID: "c", Ordinal: 2, Hash: "h2"}})
+	if err != nil || len(outcomes) != 2 || outcomes[1] != (GameOutcome{ID: "c", Ordinal: 2, Status: GameSkipped, DuplicateOf: "b"}) {
+		t.Errorf("Expected both games to be skipped, got %+v (err %v)", outcomes, err)
+	}
+
+	store.DeleteGameReport(ctx, "b")
+	if outcomes, _ := SkipStoredGameReports(ctx, store, []reporter.GameReport{{ID: "a", Hash: "h1"}, {ID: "b", Hash: "h2"}}); outcomes != nil {
+		t.Errorf("Expected nothing to be skipped once game b is deleted, got %+v", outcomes)
+	}
+}
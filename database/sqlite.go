@@ -0,0 +1,812 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite" // Pure Go driver, registers "sqlite"; keeps CGO_ENABLED=0 builds working
+	"quake_log_parser/parser"
+	"quake_log_parser/rating"
+	"quake_log_parser/reporter"
+)
+
+// DefaultSQLitePath is the database file used when neither a path nor SQLITE_PATH is given.
+const DefaultSQLitePath = "quake_reports.db"
+
+// worldName is the killer name reports use for environmental deaths. It gets no players row.
+const worldName = "<world>"
+
+// sqliteMigrations are applied in order, each once, and recorded in schema_migrations.
+// Never edit a released migration: append a new one instead.
+//
+// The games table keeps the searchable columns plus the full report as JSON, which is what game reads return.
+// Players, their per-game lines, kills and means are normalized: the player ranking and kill matrices are computed from them.
+var sqliteMigrations = []string{
+	// 1: games, players, means and kills
+	`CREATE TABLE games (
+		id               INTEGER PRIMARY KEY,
+		map              TEXT    NOT NULL,
+		game_type        INTEGER NOT NULL,
+		hostname         TEXT    NOT NULL,
+		frag_limit       INTEGER NOT NULL,
+		time_limit       INTEGER NOT NULL,
+		start_time       TEXT    NOT NULL,
+		end_time         TEXT    NOT NULL,
+		duration_seconds INTEGER NOT NULL,
+		exit_reason      TEXT    NOT NULL,
+		aborted          INTEGER NOT NULL,
+		total_kills      INTEGER NOT NULL,
+		report           TEXT    NOT NULL
+	);
+	CREATE INDEX games_exit_reason ON games (exit_reason);
+	CREATE INDEX games_duration ON games (duration_seconds);
+
+	CREATE TABLE players (
+		id   INTEGER PRIMARY KEY,
+		name TEXT NOT NULL UNIQUE
+	);
+
+	CREATE TABLE game_players (
+		game_id   INTEGER NOT NULL REFERENCES games (id) ON DELETE CASCADE,
+		player_id INTEGER NOT NULL REFERENCES players (id),
+		score     INTEGER NOT NULL,
+		kills     INTEGER NOT NULL,
+		deaths    INTEGER NOT NULL,
+		PRIMARY KEY (game_id, player_id)
+	);
+	CREATE INDEX game_players_player ON game_players (player_id);
+
+	CREATE TABLE means (
+		name     TEXT PRIMARY KEY,
+		label    TEXT NOT NULL,
+		category TEXT NOT NULL
+	);
+
+	CREATE TABLE kills (
+		game_id   INTEGER NOT NULL REFERENCES games (id) ON DELETE CASCADE,
+		seq       INTEGER NOT NULL,
+		time      TEXT    NOT NULL,
+		seconds   INTEGER NOT NULL,
+		killer_id INTEGER REFERENCES players (id), -- NULL for <world>
+		victim_id INTEGER NOT NULL REFERENCES players (id),
+		means     TEXT    NOT NULL REFERENCES means (name),
+		PRIMARY KEY (game_id, seq)
+	);
+	CREATE INDEX kills_killer ON kills (killer_id);
+	CREATE INDEX kills_victim ON kills (victim_id);`,
+
+	// 2: player ratings
+	`CREATE TABLE player_ratings (
+		player_id INTEGER PRIMARY KEY REFERENCES players (id),
+		rating    REAL    NOT NULL,
+		peak      REAL    NOT NULL,
+		games     INTEGER NOT NULL
+	);
+
+	CREATE TABLE rating_history (
+		player_id INTEGER NOT NULL REFERENCES player_ratings (player_id) ON DELETE CASCADE,
+		seq       INTEGER NOT NULL,
+		game_id   INTEGER NOT NULL,
+		rating    REAL    NOT NULL,
+		change    REAL    NOT NULL,
+		PRIMARY KEY (player_id, seq)
+	);`,
+
+	// 3: text game IDs, with the game's source log, position in it, content hash and upload time.
+	// Existing games keep their number as ID and ordinal.
+	`CREATE TABLE games_v3 (
+		id               TEXT    PRIMARY KEY,
+		source           TEXT    NOT NULL,
+		ordinal          INTEGER NOT NULL,
+		hash             TEXT    NOT NULL,
+		uploaded_at      TEXT    NOT NULL, -- UTC in sqliteTimeLayout, so it sorts as text
+		map              TEXT    NOT NULL,
+		game_type        INTEGER NOT NULL,
+		hostname         TEXT    NOT NULL,
+		frag_limit       INTEGER NOT NULL,
+		time_limit       INTEGER NOT NULL,
+		start_time       TEXT    NOT NULL,
+		end_time         TEXT    NOT NULL,
+		duration_seconds INTEGER NOT NULL,
+		exit_reason      TEXT    NOT NULL,
+		aborted          INTEGER NOT NULL,
+		total_kills      INTEGER NOT NULL,
+		report           TEXT    NOT NULL
+	);
+	INSERT INTO games_v3
+		SELECT CAST(id AS TEXT), '', id, '', '', map, game_type, hostname, frag_limit, time_limit, start_time, end_time,
+			duration_seconds, exit_reason, aborted, total_kills, json_set(report, '$.id', CAST(id AS TEXT), '$.ordinal', id)
+		FROM games;
+
+	CREATE TABLE game_players_v3 (
+		game_id   TEXT    NOT NULL REFERENCES games_v3 (id) ON DELETE CASCADE,
+		player_id INTEGER NOT NULL REFERENCES players (id),
+		score     INTEGER NOT NULL,
+		kills     INTEGER NOT NULL,
+		deaths    INTEGER NOT NULL,
+		PRIMARY KEY (game_id, player_id)
+	);
+	INSERT INTO game_players_v3 SELECT CAST(game_id AS TEXT), player_id, score, kills, deaths FROM game_players;
+
+	CREATE TABLE kills_v3 (
+		game_id   TEXT    NOT NULL REFERENCES games_v3 (id) ON DELETE CASCADE,
+		seq       INTEGER NOT NULL,
+		time      TEXT    NOT NULL,
+		seconds   INTEGER NOT NULL,
+		killer_id INTEGER REFERENCES players (id), -- NULL for <world>
+		victim_id INTEGER NOT NULL REFERENCES players (id),
+		means     TEXT    NOT NULL REFERENCES means (name),
+		PRIMARY KEY (game_id, seq)
+	);
+	INSERT INTO kills_v3 SELECT CAST(game_id AS TEXT), seq, time, seconds, killer_id, victim_id, means FROM kills;
+
+	CREATE TABLE rating_history_v3 (
+		player_id INTEGER NOT NULL REFERENCES player_ratings (player_id) ON DELETE CASCADE,
+		seq       INTEGER NOT NULL,
+		game_id   TEXT    NOT NULL,
+		rating    REAL    NOT NULL,
+		change    REAL    NOT NULL,
+		PRIMARY KEY (player_id, seq)
+	);
+	INSERT INTO rating_history_v3 SELECT player_id, seq, CAST(game_id AS TEXT), rating, change FROM rating_history;
+
+	-- Children first, so dropping games cascades to nothing
+	DROP TABLE kills;
+	DROP TABLE game_players;
+	DROP TABLE games;
+	DROP TABLE rating_history;
+	-- Renaming also rewrites the REFERENCES games_v3 clauses
+	ALTER TABLE games_v3 RENAME TO games;
+	ALTER TABLE game_players_v3 RENAME TO game_players;
+	ALTER TABLE kills_v3 RENAME TO kills;
+	ALTER TABLE rating_history_v3 RENAME TO rating_history;
+
+	CREATE INDEX games_exit_reason ON games (exit_reason);
+	CREATE INDEX games_duration ON games (duration_seconds);
+	CREATE INDEX games_stored_order ON games (uploaded_at, source, ordinal, id);
+	CREATE INDEX game_players_player ON game_players (player_id);
+	CREATE INDEX kills_killer ON kills (killer_id);
+	CREATE INDEX kills_victim ON kills (victim_id);`,
+
+	// 4: uploads, and the upload that first stored each game. Existing games have none.
+	`CREATE TABLE uploads (
+		id             TEXT    PRIMARY KEY,
+		filename       TEXT    NOT NULL,
+		size           INTEGER NOT NULL,
+		sha256         TEXT    NOT NULL,
+		source         TEXT    NOT NULL,
+		uploader       TEXT    NOT NULL,
+		client_ip      TEXT    NOT NULL,
+		uploaded_at    TEXT    NOT NULL, -- UTC in sqliteTimeLayout
+		parser_version TEXT    NOT NULL,
+		parse_mode     TEXT    NOT NULL
+	);
+	CREATE INDEX uploads_sha256 ON uploads (sha256);
+	CREATE INDEX uploads_uploaded_at ON uploads (uploaded_at);
+
+	-- Every game in the file, stored by this upload or not, so no foreign key to games
+	CREATE TABLE upload_games (
+		upload_id TEXT    NOT NULL REFERENCES uploads (id) ON DELETE CASCADE,
+		seq       INTEGER NOT NULL,
+		game_id   TEXT    NOT NULL,
+		PRIMARY KEY (upload_id, seq)
+	);
+
+	ALTER TABLE games ADD COLUMN upload_id TEXT NOT NULL DEFAULT '';
+	CREATE INDEX games_upload ON games (upload_id);`,
+
+	// 5: look up games by content hash, to find duplicates on upload
+	`CREATE INDEX games_hash ON games (hash);`,
+
+	// 6: what the ranking needs besides score, kills and deaths, filled in from the stored reports.
+	// See reporter.SecondsPlayed and reporter.GameWinners.
+	`ALTER TABLE game_players ADD COLUMN seconds_played INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE game_players ADD COLUMN won INTEGER NOT NULL DEFAULT 0;
+
+	UPDATE game_players SET seconds_played = COALESCE(
+		(SELECT json_extract(s.value, '$.seconds_played')
+			FROM games g, json_each(g.report, '$.sessions') s, players p
+			WHERE g.id = game_players.game_id AND p.id = game_players.player_id AND s.key = p.name),
+		(SELECT duration_seconds FROM games g WHERE g.id = game_players.game_id));
+
+	UPDATE game_players SET won = CASE
+		WHEN (SELECT json_extract(g.report, '$.teams.winner') FROM games g WHERE g.id = game_players.game_id) IN ('red', 'blue')
+		THEN EXISTS (SELECT 1
+			FROM games g, json_each(g.report, '$.teams.' || json_extract(g.report, '$.teams.winner') || '.players') t, players p
+			WHERE g.id = game_players.game_id AND p.id = game_players.player_id AND t.value = p.name)
+		ELSE score = (SELECT MAX(o.score) FROM game_players o WHERE o.game_id = game_players.game_id)
+	END;`,
+}
+
+// sqliteTimeLayout stores times in UTC with a fixed width, so they sort as text.
+const sqliteTimeLayout = "2006-01-02T15:04:05.000000000Z07:00"
+
+// SQLiteStore is a GameStore kept in a single SQLite file.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// OpenSQLiteStore opens (creating it if needed) the SQLite database at path and brings its schema up to date.
+// An empty path falls back to the SQLITE_PATH environment variable, then DefaultSQLitePath.
+// The caller is responsible for calling Close.
+func OpenSQLiteStore(ctx context.Context, path string) (*SQLiteStore, error) {
+	if path == "" {
+		path = os.Getenv("SQLITE_PATH")
+	}
+	if path == "" {
+		path = DefaultSQLitePath
+	}
+
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open SQLite database %s: %w", path, err)
+	}
+	// SQLite allows a single writer; one connection also keeps ":memory:" databases from splitting per connection
+	db.SetMaxOpenConns(1)
+
+	store := &SQLiteStore{db: db}
+	if err := store.migrate(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate SQLite database %s: %w", path, err)
+	}
+	return store, nil
+}
+
+// Close closes the database.
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// migrate applies the migrations the database has not seen yet, each in its own transaction.
+func (s *SQLiteStore) migrate(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	if err != nil {
+		return err
+	}
+
+	var current int
+	if err := s.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current); err != nil {
+		return err
+	}
+	if current > len(sqliteMigrations) {
+		return fmt.Errorf("database schema version %d is newer than this build supports (%d)", current, len(sqliteMigrations))
+	}
+
+	for version := current + 1; version <= len(sqliteMigrations); version++ {
+		err := s.inTx(ctx, func(tx *sql.Tx) error {
+			if _, err := tx.ExecContext(ctx, sqliteMigrations[version-1]); err != nil {
+				return err
+			}
+			_, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version) VALUES (?)`, version)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("migration %d: %w", version, err)
+		}
+	}
+	return nil
+}
+
+// inTx runs fn in a transaction, committing when it returns nil and rolling back otherwise.
+func (s *SQLiteStore) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) StoreGameReports(ctx context.Context, reports []reporter.GameReport) ([]string, error) {
+	stored := []string{}
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		for _, report := range reports {
+			var exists bool
+			if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM games WHERE id = ?)`, report.ID).Scan(&exists); err != nil {
+				return err
+			}
+			if exists {
+				continue // Already stored
+			}
+			if err := storeGameReport(ctx, tx, report); err != nil {
+				return fmt.Errorf("game %s: %w", report.ID, err)
+			}
+			stored = append(stored, report.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store game reports: %w", err)
+	}
+	return stored, nil
+}
+
+// storeGameReport inserts the game with its player lines and kills.
+func storeGameReport(ctx context.Context, tx *sql.Tx, report reporter.GameReport) error {
+	document, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `INSERT INTO games
+		(id, source, ordinal, hash, uploaded_at, upload_id, map, game_type, hostname, frag_limit, time_limit, start_time, end_time, duration_seconds, exit_reason, aborted, total_kills, report)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		report.ID, report.Source, report.Ordinal, report.Hash, report.UploadedAt.UTC().Format(sqliteTimeLayout), report.UploadID,
+		report.Map, report.GameType, report.Hostname, report.FragLimit, report.TimeLimit,
+		report.StartTime, report.EndTime, report.DurationSeconds, report.ExitReason, report.Aborted, report.TotalKills, string(document))
+	if err != nil {
+		return err
+	}
+
+	playerIDs := make(map[string]int64)
+	playerID := func(name string) (int64, error) {
+		if id, ok := playerIDs[name]; ok {
+			return id, nil
+		}
+		id, err := upsertPlayer(ctx, tx, name)
+		playerIDs[name] = id
+		return id, err
+	}
+
+	winners := reporter.GameWinners(report)
+	for _, name := range report.Players {
+		id, err := playerID(name)
+		if err != nil {
+			return err
+		}
+		stats := report.PlayerStats[name]
+		_, err = tx.ExecContext(ctx, `INSERT INTO game_players (game_id, player_id, score, kills, deaths, seconds_played, won) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			report.ID, id, report.Kills[name], stats.Kills, stats.Deaths, reporter.SecondsPlayed(report, name), winners[name])
+		if err != nil {
+			return err
+		}
+	}
+
+	for i, kill := range report.KillLog {
+		var killerID sql.NullInt64
+		if kill.Killer != worldName {
+			id, err := playerID(kill.Killer)
+			if err != nil {
+				return err
+			}
+			killerID = sql.NullInt64{Int64: id, Valid: true}
+		}
+		victimID, err := playerID(kill.Victim)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `INSERT INTO means (name, label, category) VALUES (?, ?, ?) ON CONFLICT (name) DO NOTHING`,
+			kill.Means, parser.MeansLabel(kill.Means), parser.MeansCategory(kill.Means))
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `INSERT INTO kills (game_id, seq, time, seconds, killer_id, victim_id, means) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			report.ID, i, kill.Time, kill.Seconds, killerID, victimID, kill.Means)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// upsertPlayer returns the ID of the player called name, adding them if needed.
+func upsertPlayer(ctx context.Context, tx *sql.Tx, name string) (int64, error) {
+	var id int64
+	err := tx.QueryRowContext(ctx, `INSERT INTO players (name) VALUES (?) ON CONFLICT (name) DO UPDATE SET name = excluded.name RETURNING id`, name).Scan(&id)
+	return id, err
+}
+
+// prunePlayers deletes the players no game, kill or rating refers to any more.
+func prunePlayers(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `DELETE FROM players WHERE
+		id NOT IN (SELECT player_id FROM game_players) AND
+		id NOT IN (SELECT killer_id FROM kills WHERE killer_id IS NOT NULL) AND
+		id NOT IN (SELECT victim_id FROM kills) AND
+		id NOT IN (SELECT player_id FROM player_ratings)`)
+	return err
+}
+
+func (s *SQLiteStore) GetGameReport(ctx context.Context, gameID string) (*reporter.GameReport, error) {
+	var document string
+	err := s.db.QueryRowContext(ctx, `SELECT report FROM games WHERE id = ?`, gameID).Scan(&document)
+	if err == sql.ErrNoRows {
+		return nil, nil // Not found
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find game report with ID %s: %w", gameID, err)
+	}
+	var report reporter.GameReport
+	if err := json.Unmarshal([]byte(document), &report); err != nil {
+		return nil, fmt.Errorf("failed to decode game report with ID %s: %w", gameID, err)
+	}
+	return &report, nil
+}
+
+func (s *SQLiteStore) FindGameReports(ctx context.Context, filter GameFilter) ([]reporter.GameReport, error) {
+	where, args := filter.toSQL()
+	rows, err := s.db.QueryContext(ctx, `SELECT report FROM games`+where+` ORDER BY uploaded_at, source, ordinal, id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find game reports: %w", err)
+	}
+	defer rows.Close()
+
+	reports := []reporter.GameReport{}
+	for rows.Next() {
+		var document string
+		if err := rows.Scan(&document); err != nil {
+			return nil, fmt.Errorf("failed to read game report: %w", err)
+		}
+		var report reporter.GameReport
+		if err := json.Unmarshal([]byte(document), &report); err != nil {
+			return nil, fmt.Errorf("failed to decode game report: %w", err)
+		}
+		reports = append(reports, report)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read game reports: %w", err)
+	}
+	return reports, nil
+}
+
+func (s *SQLiteStore) FindGameIDsByHash(ctx context.Context, hashes []string) (map[string]string, error) {
+	found := make(map[string]string)
+	if len(hashes) == 0 {
+		return found, nil
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(hashes)), ", ")
+	args := make([]interface{}, len(hashes))
+	for i, hash := range hashes {
+		args[i] = hash
+	}
+	rows, err := s.db.QueryContext(ctx, `SELECT hash, id FROM games WHERE hash IN (`+placeholders+`)
+		ORDER BY uploaded_at, source, ordinal, id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find games by hash: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var hash, id string
+		if err := rows.Scan(&hash, &id); err != nil {
+			return nil, fmt.Errorf("failed to read game: %w", err)
+		}
+		if _, ok := found[hash]; !ok {
+			found[hash] = id
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to find games by hash: %w", err)
+	}
+	return found, nil
+}
+
+// toSQL translates the filter into a WHERE clause (empty when nothing is filtered) and its arguments.
+// It matches what toBSON selects in MongoDB.
+func (f GameFilter) toSQL() (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	if f.ExitReason != "" {
+		escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(f.ExitReason)
+		conditions = append(conditions, `exit_reason LIKE ? ESCAPE '\'`) // LIKE is case-insensitive for ASCII
+		args = append(args, "%"+escaped+"%")
+	}
+	if f.Aborted != nil {
+		conditions = append(conditions, `aborted = ?`)
+		args = append(args, *f.Aborted)
+	}
+	if f.MinDuration > 0 {
+		conditions = append(conditions, `duration_seconds >= ?`)
+		args = append(args, f.MinDuration)
+	}
+	if f.MaxDuration > 0 {
+		conditions = append(conditions, `duration_seconds <= ?`)
+		args = append(args, f.MaxDuration)
+	}
+	if f.UploadID != "" {
+		conditions = append(conditions, `upload_id = ?`)
+		args = append(args, f.UploadID)
+	}
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+func (s *SQLiteStore) DeleteGameReport(ctx context.Context, gameID string) (bool, error) {
+	var deleted int64
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, `DELETE FROM games WHERE id = ?`, gameID)
+		if err != nil {
+			return err
+		}
+		if deleted, err = result.RowsAffected(); err != nil {
+			return err
+		}
+		return prunePlayers(ctx, tx)
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to delete game report with ID %s: %w", gameID, err)
+	}
+	return deleted > 0, nil
+}
+
+func (s *SQLiteStore) DeleteAllGameReports(ctx context.Context) error {
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM games`); err != nil {
+			return err
+		}
+		return prunePlayers(ctx, tx)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete all game reports: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) RankPlayers(ctx context.Context, opts reporter.RankingOptions) ([]reporter.PlayerRankEntry, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT p.name, COUNT(*), SUM(gp.score), SUM(gp.kills), SUM(gp.deaths), SUM(gp.seconds_played), SUM(gp.won)
+		FROM game_players gp
+		JOIN players p ON p.id = gp.player_id
+		GROUP BY p.id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rank players: %w", err)
+	}
+	defer rows.Close()
+
+	totals := []reporter.PlayerRankEntry{}
+	for rows.Next() {
+		var e reporter.PlayerRankEntry
+		if err := rows.Scan(&e.PlayerName, &e.Games, &e.TotalKills, &e.Kills, &e.Deaths, &e.SecondsPlayed, &e.Wins); err != nil {
+			return nil, fmt.Errorf("failed to read player totals: %w", err)
+		}
+		totals = append(totals, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to rank players: %w", err)
+	}
+	return reporter.RankTotals(totals, opts), nil
+}
+
+func (s *SQLiteStore) GetKillMatrix(ctx context.Context, gameID string) (map[string]map[string]int, error) {
+	var exists bool
+	if err := s.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM games WHERE id = ?)`, gameID).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to find game with ID %s: %w", gameID, err)
+	}
+	if !exists {
+		return nil, nil // Not found
+	}
+
+	// Suicides, kills between same-named slots and <world> (a NULL killer) are left out, as in the parser's matrix
+	rows, err := s.db.QueryContext(ctx, `SELECT killer.name, victim.name, COUNT(*)
+		FROM kills k
+		JOIN players killer ON killer.id = k.killer_id
+		JOIN players victim ON victim.id = k.victim_id
+		WHERE k.game_id = ? AND k.killer_id <> k.victim_id
+		GROUP BY k.killer_id, k.victim_id`, gameID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute kill matrix of game %s: %w", gameID, err)
+	}
+	defer rows.Close()
+
+	matrix := map[string]map[string]int{}
+	for rows.Next() {
+		var killer, victim string
+		var count int
+		if err := rows.Scan(&killer, &victim, &count); err != nil {
+			return nil, fmt.Errorf("failed to read kill matrix of game %s: %w", gameID, err)
+		}
+		if matrix[killer] == nil {
+			matrix[killer] = map[string]int{}
+		}
+		matrix[killer][victim] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to compute kill matrix of game %s: %w", gameID, err)
+	}
+	return matrix, nil
+}
+
+func (s *SQLiteStore) ReplaceRatings(ctx context.Context, ratings []rating.PlayerRating) error {
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		// The cascade clears rating_history
+		if _, err := tx.ExecContext(ctx, `DELETE FROM player_ratings`); err != nil {
+			return err
+		}
+		for _, r := range ratings {
+			playerID, err := upsertPlayer(ctx, tx, r.Name)
+			if err != nil {
+				return err
+			}
+			_, err = tx.ExecContext(ctx, `INSERT INTO player_ratings (player_id, rating, peak, games) VALUES (?, ?, ?, ?)`,
+				playerID, r.Rating, r.Peak, r.Games)
+			if err != nil {
+				return err
+			}
+			for i, entry := range r.History {
+				_, err = tx.ExecContext(ctx, `INSERT INTO rating_history (player_id, seq, game_id, rating, change) VALUES (?, ?, ?, ?, ?)`,
+					playerID, i, entry.GameID, entry.Rating, entry.Change)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return prunePlayers(ctx, tx)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to replace ratings: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) GetRatings(ctx context.Context) ([]rating.PlayerRating, error) {
+	ratings, err := s.queryRatings(ctx, ``)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve ratings: %w", err)
+	}
+	return ratings, nil
+}
+
+func (s *SQLiteStore) GetRating(ctx context.Context, name string) (*rating.PlayerRating, error) {
+	ratings, err := s.queryRatings(ctx, ` WHERE p.name = ?`, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve rating of %s: %w", name, err)
+	}
+	if len(ratings) == 0 {
+		return nil, nil // Not found
+	}
+	return &ratings[0], nil
+}
+
+// queryRatings returns the ratings selected by where, best first, with their history.
+func (s *SQLiteStore) queryRatings(ctx context.Context, where string, args ...interface{}) ([]rating.PlayerRating, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT p.name, r.rating, r.peak, r.games, h.game_id, h.rating, h.change
+		FROM player_ratings r
+		JOIN players p ON p.id = r.player_id
+		LEFT JOIN rating_history h ON h.player_id = r.player_id`+where+`
+		ORDER BY r.rating DESC, p.name, h.seq`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ratings := []rating.PlayerRating{}
+	for rows.Next() {
+		var r rating.PlayerRating
+		var gameID sql.NullString
+		var historyRating, change sql.NullFloat64
+		if err := rows.Scan(&r.Name, &r.Rating, &r.Peak, &r.Games, &gameID, &historyRating, &change); err != nil {
+			return nil, err
+		}
+		if len(ratings) == 0 || ratings[len(ratings)-1].Name != r.Name {
+			ratings = append(ratings, r)
+		}
+		if gameID.Valid {
+			last := &ratings[len(ratings)-1]
+			last.History = append(last.History, rating.HistoryEntry{GameID: gameID.String, Rating: historyRating.Float64, Change: change.Float64})
+		}
+	}
+	return ratings, rows.Err()
+}
+
+func (s *SQLiteStore) StoreUpload(ctx context.Context, upload Upload) error {
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `INSERT INTO uploads
+			(id, filename, size, sha256, source, uploader, client_ip, uploaded_at, parser_version, parse_mode)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			upload.ID, upload.Filename, upload.Size, upload.SHA256, upload.Source, upload.Uploader, upload.ClientIP,
+			upload.UploadedAt.UTC().Format(sqliteTimeLayout), upload.ParserVersion, upload.ParseMode)
+		if err != nil {
+			return err
+		}
+		for i, gameID := range upload.GameIDs {
+			if _, err := tx.ExecContext(ctx, `INSERT INTO upload_games (upload_id, seq, game_id) VALUES (?, ?, ?)`, upload.ID, i, gameID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store upload %s: %w", upload.ID, err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) GetUpload(ctx context.Context, uploadID string) (*Upload, error) {
+	uploads, err := s.queryUploads(ctx, ` WHERE u.id = ?`, uploadID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve upload %s: %w", uploadID, err)
+	}
+	if len(uploads) == 0 {
+		return nil, nil // Not found
+	}
+	return &uploads[0], nil
+}
+
+func (s *SQLiteStore) ListUploads(ctx context.Context) ([]Upload, error) {
+	uploads, err := s.queryUploads(ctx, ``)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve uploads: %w", err)
+	}
+	return uploads, nil
+}
+
+func (s *SQLiteStore) FindUploadsBySHA256(ctx context.Context, sha256 string) ([]Upload, error) {
+	uploads, err := s.queryUploads(ctx, ` WHERE u.sha256 = ?`, sha256)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve uploads by SHA-256: %w", err)
+	}
+	return uploads, nil
+}
+
+// queryUploads returns the uploads selected by where, newest first, with their game IDs.
+func (s *SQLiteStore) queryUploads(ctx context.Context, where string, args ...interface{}) ([]Upload, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT u.id, u.filename, u.size, u.sha256, u.source, u.uploader, u.client_ip,
+			u.uploaded_at, u.parser_version, u.parse_mode, g.game_id
+		FROM uploads u
+		LEFT JOIN upload_games g ON g.upload_id = u.id`+where+`
+		ORDER BY u.uploaded_at DESC, u.id, g.seq`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	uploads := []Upload{}
+	for rows.Next() {
+		var u Upload
+		var uploadedAt string
+		var gameID sql.NullString
+		err := rows.Scan(&u.ID, &u.Filename, &u.Size, &u.SHA256, &u.Source, &u.Uploader, &u.ClientIP,
+			&uploadedAt, &u.ParserVersion, &u.ParseMode, &gameID)
+		if err != nil {
+			return nil, err
+		}
+		if len(uploads) == 0 || uploads[len(uploads)-1].ID != u.ID {
+			if u.UploadedAt, err = time.Parse(sqliteTimeLayout, uploadedAt); err != nil {
+				return nil, err
+			}
+			u.GameIDs = []string{}
+			uploads = append(uploads, u)
+		}
+		if gameID.Valid {
+			last := &uploads[len(uploads)-1]
+			last.GameIDs = append(last.GameIDs, gameID.String)
+		}
+	}
+	return uploads, rows.Err()
+}
+
+func (s *SQLiteStore) DeleteUpload(ctx context.Context, uploadID string) (bool, int, error) {
+	var found, deleted int64
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		// upload_games follows the upload by cascade; games only point at it by value
+		result, err := tx.ExecContext(ctx, `DELETE FROM games WHERE upload_id = ?`, uploadID)
+		if err != nil {
+			return err
+		}
+		if deleted, err = result.RowsAffected(); err != nil {
+			return err
+		}
+		result, err = tx.ExecContext(ctx, `DELETE FROM uploads WHERE id = ?`, uploadID)
+		if err != nil {
+			return err
+		}
+		if found, err = result.RowsAffected(); err != nil {
+			return err
+		}
+		return prunePlayers(ctx, tx)
+	})
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to delete upload %s: %w", uploadID, err)
+	}
+	return found > 0, int(deleted), nil
+}
@@ -0,0 +1,489 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"quake_log_parser/parser"
+	"quake_log_parser/rating"
+	"quake_log_parser/reporter"
+)
+
+func openTestSQLiteStore(t *testing.T) (*SQLiteStore, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.db")
+	store, err := OpenSQLiteStore(context.Background(), path)
+	if err != nil {
+		t.Fatalf("OpenSQLiteStore returned an error: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store, path
+}
+
+func sqliteTestReport(id string) reporter.GameReport {
+	return reporter.GameReport{
+		ID:              id,
+		Source:          "upload",
+		Ordinal:         4,
+		UploadedAt:      time.Date(2026, 1, 2, 15, 4, 5, 6, time.FixedZone("BRT", -3*3600)),
+		TotalKills:      3,
+		Players:         []string{"Isgalamido", "Dono da Bola"},
+		Kills:           map[string]int{"Isgalamido": 1, "Dono da Bola": -1},
+		Map:             "q3dm17",
+		ExitReason:      "Fraglimit hit.",
+		DurationSeconds: 115,
+		PlayerStats: map[string]reporter.PlayerStats{
+			"Isgalamido":   {Score: 1, Kills: 2, Deaths: 0},
+			"Dono da Bola": {Score: -1, Kills: 0, Deaths: 3, WorldDeaths: 1},
+		},
+		KillLog: []reporter.KillEntry{
+			{Time: "1:10", Seconds: 70, Killer: "Isgalamido", Victim: "Dono da Bola", Means: "MOD_ROCKET_SPLASH"},
+			{Time: "1:26", Seconds: 86, Killer: "<world>", Victim: "Dono da Bola", Means: "MOD_TRIGGER_HURT"},
+			{Time: "1:41", Seconds: 101, Killer: "Isgalamido", Victim: "Dono da Bola", Means: "MOD_RAILGUN"},
+		},
+	}
+}
+
+func TestSQLiteStore_RoundTrip(t *testing.T) {
+	store, _ := openTestSQLiteStore(t)
+	ctx := context.Background()
+
+	stored, err := store.StoreGameReports(ctx, []reporter.GameReport{sqliteTestReport("04")})
+	if err != nil || len(stored) != 1 || stored[0] != "04" {
+		t.Fatalf("Expected game 04 to be stored, got %v (err %v)", stored, err)
+	}
+
+	report, err := store.GetGameReport(ctx, "04")
+	if err != nil || report == nil {
+		t.Fatalf("Expected game 04, got %+v (err %v)", report, err)
+	}
+	if report.ID != "04" || report.Map != "q3dm17" || len(report.KillLog) != 3 || report.PlayerStats["Dono da Bola"].WorldDeaths != 1 {
+		t.Errorf("Report did not survive the round trip: %+v", report)
+	}
+	if !report.UploadedAt.Equal(sqliteTestReport("").UploadedAt) || report.Ordinal != 4 {
+		t.Errorf("Expected the upload time and ordinal to survive the round trip, got %v and %d", report.UploadedAt, report.Ordinal)
+	}
+	if report, _ := store.GetGameReport(ctx, "4"); report != nil {
+		t.Errorf("Expected no game 4, got %+v", report)
+	}
+
+	// The normalized tables hold the same game
+	var players, kills, worldKills, means int
+	store.db.QueryRow(`SELECT COUNT(*) FROM game_players WHERE game_id = '04'`).Scan(&players)
+	store.db.QueryRow(`SELECT COUNT(*) FROM kills WHERE game_id = '04'`).Scan(&kills)
+	store.db.QueryRow(`SELECT COUNT(*) FROM kills WHERE game_id = '04' AND killer_id IS NULL`).Scan(&worldKills)
+	store.db.QueryRow(`SELECT COUNT(*) FROM means`).Scan(&means)
+	if players != 2 || kills != 3 || worldKills != 1 || means != 3 {
+		t.Errorf("Expected 2 players, 3 kills (1 by <world>) and 3 means, got %d, %d (%d), %d", players, kills, worldKills, means)
+	}
+	var label, category string
+	store.db.QueryRow(`SELECT label, category FROM means WHERE name = 'MOD_RAILGUN'`).Scan(&label, &category)
+	if label != "Railgun" || category != "direct" {
+		t.Errorf("Expected MOD_RAILGUN to be a direct Railgun kill, got %q %q", label, category)
+	}
+
+	// Storing the game again keeps the first copy instead of duplicating its rows
+	again := sqliteTestReport("04")
+	again.Map = "q3dm6"
+	if stored, _ := store.StoreGameReports(ctx, []reporter.GameReport{again}); len(stored) != 0 {
+		t.Errorf("Expected nothing to be stored again, got %v", stored)
+	}
+	store.db.QueryRow(`SELECT COUNT(*) FROM kills WHERE game_id = '04'`).Scan(&kills)
+	if report, _ := store.GetGameReport(ctx, "04"); report.Map != "q3dm17" || kills != 3 {
+		t.Errorf("Expected game 04 on q3dm17 with 3 kills, got %q with %d", report.Map, kills)
+	}
+}
+
+func TestSQLiteStore_FindGameReports(t *testing.T) {
+	store, _ := openTestSQLiteStore(t)
+	ctx := context.Background()
+	aborted := true
+	first := time.Date(2026, 1, 2, 15, 0, 0, 0, time.UTC)
+	store.StoreGameReports(ctx, []reporter.GameReport{
+		{ID: "d", Ordinal: 1, UploadedAt: first.Add(time.Hour), ExitReason: "100%_done", DurationSeconds: 60},
+		{ID: "c", Ordinal: 3, UploadedAt: first, ExitReason: "Timelimit hit.", DurationSeconds: 900},
+		{ID: "a", Ordinal: 1, UploadedAt: first, ExitReason: "Fraglimit hit.", DurationSeconds: 120},
+		{ID: "b", Ordinal: 2, UploadedAt: first, Aborted: true, DurationSeconds: 30},
+	})
+
+	tests := []struct {
+		name   string
+		filter GameFilter
+		want   []string
+	}{
+		{"stored order", GameFilter{}, []string{"a", "b", "c", "d"}},
+		{"exit reason", GameFilter{ExitReason: "FRAG"}, []string{"a"}},
+		{"wildcards are literal", GameFilter{ExitReason: "%_"}, []string{"d"}},
+		{"aborted", GameFilter{Aborted: &aborted}, []string{"b"}},
+		{"duration range", GameFilter{MinDuration: 60, MaxDuration: 600}, []string{"a", "d"}},
+		{"no match", GameFilter{ExitReason: "capturelimit"}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reports, err := store.FindGameReports(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("FindGameReports returned an error: %v", err)
+			}
+			if reports == nil {
+				t.Fatal("Expected an empty slice, got nil")
+			}
+			if len(reports) != len(tt.want) {
+				t.Fatalf("Expected games %v, got %+v", tt.want, reports)
+			}
+			for i, id := range tt.want {
+				if reports[i].ID != id {
+					t.Errorf("Expected game %s at %d, got %s", id, i, reports[i].ID)
+				}
+			}
+		})
+	}
+}
+
+func TestSQLiteStore_DeleteCascades(t *testing.T) {
+	store, _ := openTestSQLiteStore(t)
+	ctx := context.Background()
+	other := reporter.GameReport{ID: "2", Players: []string{"Zeh"}, Kills: map[string]int{"Zeh": 0}}
+	store.StoreGameReports(ctx, []reporter.GameReport{sqliteTestReport("1"), other})
+
+	if deleted, err := store.DeleteGameReport(ctx, "1"); err != nil || !deleted {
+		t.Fatalf("Expected game 1 to be deleted, got %v (err %v)", deleted, err)
+	}
+	if deleted, _ := store.DeleteGameReport(ctx, "1"); deleted {
+		t.Error("Expected a second delete of game 1 to find nothing")
+	}
+
+	var kills, players int
+	store.db.QueryRow(`SELECT COUNT(*) FROM kills`).Scan(&kills)
+	store.db.QueryRow(`SELECT COUNT(*) FROM players`).Scan(&players)
+	if kills != 0 || players != 1 {
+		t.Errorf("Expected the kills and players of game 1 to go with it, got %d kills and %d players", kills, players)
+	}
+
+	if err := store.DeleteAllGameReports(ctx); err != nil {
+		t.Fatalf("DeleteAllGameReports returned an error: %v", err)
+	}
+	if reports, _ := store.FindGameReports(ctx, GameFilter{}); len(reports) != 0 {
+		t.Errorf("Expected no games left, got %d", len(reports))
+	}
+}
+
+func TestSQLiteStore_Ratings(t *testing.T) {
+	store, _ := openTestSQLiteStore(t)
+	ctx := context.Background()
+	store.ReplaceRatings(ctx, []rating.PlayerRating{
+		{Name: "b", Rating: 1500, Peak: 1500, Games: 1, History: []rating.HistoryEntry{{GameID: "01", Rating: 1500}}},
+		{Name: "a", Rating: 1500, Peak: 1516, Games: 2, History: []rating.HistoryEntry{{GameID: "01", Rating: 1516, Change: 16}, {GameID: "02", Rating: 1500, Change: -16}}},
+		{Name: "c", Rating: 1600, Peak: 1600, Games: 1},
+	})
+
+	ratings, err := store.GetRatings(ctx)
+	if err != nil {
+		t.Fatalf("GetRatings returned an error: %v", err)
+	}
+	if len(ratings) != 3 || ratings[0].Name != "c" || ratings[1].Name != "a" || ratings[2].Name != "b" {
+		t.Fatalf("Expected c, a, b, got %+v", ratings)
+	}
+	if len(ratings[1].History) != 2 || ratings[1].History[1] != (rating.HistoryEntry{GameID: "02", Rating: 1500, Change: -16}) || ratings[0].History != nil {
+		t.Errorf("History did not survive the round trip: %+v", ratings)
+	}
+
+	store.ReplaceRatings(ctx, []rating.PlayerRating{{Name: "a", Rating: 1510}})
+	if r, _ := store.GetRating(ctx, "c"); r != nil {
+		t.Errorf("Expected ReplaceRatings to drop c, got %+v", r)
+	}
+	if r, _ := store.GetRating(ctx, "a"); r == nil || r.Rating != 1510 || len(r.History) != 0 {
+		t.Errorf("Expected a at 1510 without history, got %+v", r)
+	}
+}
+
+func TestOpenSQLiteStore_MigratesOnce(t *testing.T) {
+	store, path := openTestSQLiteStore(t)
+	ctx := context.Background()
+	store.StoreGameReports(ctx, []reporter.GameReport{sqliteTestReport("1")})
+	store.Close()
+
+	// Reopening keeps the data and does not rerun the migrations
+	reopened, err := OpenSQLiteStore(ctx, path)
+	if err != nil {
+		t.Fatalf("Reopening returned an error: %v", err)
+	}
+	defer reopened.Close()
+	if report, _ := reopened.GetGameReport(ctx, "1"); report == nil {
+		t.Error("Expected game 1 to survive reopening the database")
+	}
+	var versions int
+	reopened.db.QueryRow(`SELECT COUNT(*) FROM schema_migrations`).Scan(&versions)
+	if versions != len(sqliteMigrations) {
+		t.Errorf("Expected %d recorded migrations, got %d", len(sqliteMigrations), versions)
+	}
+
+	// A schema newer than the build is refused
+	reopened.db.Exec(`INSERT INTO schema_migrations (version) VALUES (?)`, len(sqliteMigrations)+1)
+	reopened.Close()
+	if _, err := OpenSQLiteStore(ctx, path); err == nil {
+		t.Error("Expected opening a newer schema to fail")
+	}
+}
+
+func TestOpenSQLiteStore_MigratesNumericGameIDs(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "legacy.db")
+
+	// A database at schema version 2, when game IDs were the position in the log
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=foreign_keys(1)")
+	if err != nil {
+		t.Fatalf("Failed to open the legacy database: %v", err)
+	}
+	statements := []string{
+		`CREATE TABLE schema_migrations (version INTEGER PRIMARY KEY, applied_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP)`,
+		sqliteMigrations[0],
+		sqliteMigrations[1],
+		`INSERT INTO schema_migrations (version) VALUES (1), (2)`,
+		`INSERT INTO games VALUES (5, 'q3dm17', 0, '', 20, 15, '0:00', '1:00', 60, 'Fraglimit hit.', 0, 1, '{"id":5,"map":"q3dm17","total_kills":1}')`,
+		`INSERT INTO players (id, name) VALUES (1, 'Zeh'), (2, 'Mal')`,
+		`INSERT INTO game_players VALUES (5, 1, 1, 1, 0), (5, 2, 0, 0, 1)`,
+		`INSERT INTO means VALUES ('MOD_RAILGUN', 'Railgun', 'direct')`,
+		`INSERT INTO kills VALUES (5, 0, '0:30', 30, 1, 2, 'MOD_RAILGUN')`,
+		`INSERT INTO player_ratings VALUES (1, 1516, 1516, 1)`,
+		`INSERT INTO rating_history VALUES (1, 0, 5, 1516, 16)`,
+	}
+	for _, statement := range statements {
+		if _, err := db.Exec(statement); err != nil {
+			t.Fatalf("Failed to build the legacy database: %v", err)
+		}
+	}
+	db.Close()
+
+	store, err := OpenSQLiteStore(ctx, path)
+	if err != nil {
+		t.Fatalf("OpenSQLiteStore returned an error: %v", err)
+	}
+	defer store.Close()
+
+	report, err := store.GetGameReport(ctx, "5")
+	if err != nil || report == nil || report.ID != "5" || report.Ordinal != 5 || report.Map != "q3dm17" {
+		t.Fatalf("Expected game 5 on q3dm17 with ordinal 5, got %+v (err %v)", report, err)
+	}
+	if r, _ := store.GetRating(ctx, "Zeh"); r == nil || len(r.History) != 1 || r.History[0].GameID != "5" {
+		t.Errorf("Expected Zeh's history to point at game 5, got %+v", r)
+	}
+
+	// The foreign keys follow the renamed tables
+	if deleted, err := store.DeleteGameReport(ctx, "5"); err != nil || !deleted {
+		t.Fatalf("Expected game 5 to be deleted, got %v (err %v)", deleted, err)
+	}
+	var kills, players int
+	store.db.QueryRow(`SELECT COUNT(*) FROM kills`).Scan(&kills)
+	store.db.QueryRow(`SELECT COUNT(*) FROM game_players`).Scan(&players)
+	if kills != 0 || players != 0 {
+		t.Errorf("Expected deleting game 5 to cascade, got %d kills and %d player lines left", kills, players)
+	}
+}
+
+func TestSQLiteStore_Uploads(t *testing.T) {
+	store, _ := openTestSQLiteStore(t)
+	ctx := context.Background()
+	uploadedAt := time.Date(2026, 1, 2, 15, 4, 5, 6, time.UTC)
+	upload := Upload{
+		ID: "u1", Filename: "games.log", Size: 1234, SHA256: "abc", Source: "server-1", Uploader: "ops",
+		ClientIP: "10.0.0.1", UploadedAt: uploadedAt, ParserVersion: "1.0", ParseMode: "lenient", GameIDs: []string{"1", "2"},
+	}
+	if err := store.StoreUpload(ctx, upload); err != nil {
+		t.Fatalf("StoreUpload returned an error: %v", err)
+	}
+	store.StoreUpload(ctx, Upload{ID: "u0", UploadedAt: uploadedAt.Add(-time.Hour)})
+
+	owned := sqliteTestReport("1")
+	owned.UploadID = "u1"
+	kept := reporter.GameReport{ID: "2", Players: []string{"Zeh"}, Kills: map[string]int{"Zeh": 0}, UploadID: "u0"}
+	store.StoreGameReports(ctx, []reporter.GameReport{owned, kept})
+
+	got, err := store.GetUpload(ctx, "u1")
+	if err != nil || got == nil {
+		t.Fatalf("Expected upload u1, got %+v (err %v)", got, err)
+	}
+	if !got.UploadedAt.Equal(uploadedAt) || len(got.GameIDs) != 2 || got.GameIDs[1] != "2" || got.Uploader != "ops" {
+		t.Errorf("Expected upload u1 to round-trip, got %+v", got)
+	}
+	uploads, _ := store.ListUploads(ctx)
+	if len(uploads) != 2 || uploads[0].ID != "u1" || uploads[1].GameIDs == nil {
+		t.Errorf("Expected uploads u1, u0, got %+v", uploads)
+	}
+	if reports, _ := store.FindGameReports(ctx, GameFilter{UploadID: "u1"}); len(reports) != 1 || reports[0].ID != "1" {
+		t.Errorf("Expected upload u1 to own game 1 only, got %+v", reports)
+	}
+
+	found, deleted, err := store.DeleteUpload(ctx, "u1")
+	if err != nil || !found || deleted != 1 {
+		t.Fatalf("Expected upload u1 and 1 game to be deleted, got %v, %d (err %v)", found, deleted, err)
+	}
+	var uploadGames, kills int
+	store.db.QueryRow(`SELECT COUNT(*) FROM upload_games`).Scan(&uploadGames)
+	store.db.QueryRow(`SELECT COUNT(*) FROM kills`).Scan(&kills)
+	if uploadGames != 0 || kills != 0 {
+		t.Errorf("Expected the game list and kills of upload u1 to go with it, got %d and %d", uploadGames, kills)
+	}
+	if report, _ := store.GetGameReport(ctx, "2"); report == nil {
+		t.Error("Expected game 2, stored by u0, to survive")
+	}
+	if found, _, _ := store.DeleteUpload(ctx, "u1"); found {
+		t.Error("Expected a second delete of upload u1 to find nothing")
+	}
+}
+
+func TestSQLiteStore_FindDuplicates(t *testing.T) {
+	store, _ := openTestSQLiteStore(t)
+	ctx := context.Background()
+	older, newer := sqliteTestReport("1"), sqliteTestReport("2")
+	older.Hash, newer.Hash = "h1", "h1"
+	newer.UploadedAt = older.UploadedAt.Add(time.Minute)
+	store.StoreGameReports(ctx, []reporter.GameReport{newer, older})
+
+	found, err := store.FindGameIDsByHash(ctx, []string{"h1", "h2"})
+	if err != nil || len(found) != 1 || found["h1"] != "1" {
+		t.Errorf("Expected h1 to map to the first stored game 1, got %v (err %v)", found, err)
+	}
+	if found, err := store.FindGameIDsByHash(ctx, nil); err != nil || len(found) != 0 {
+		t.Errorf("Expected nothing for no hashes, got %v (err %v)", found, err)
+	}
+
+	store.StoreUpload(ctx, Upload{ID: "u1", SHA256: "abc", UploadedAt: older.UploadedAt})
+	store.StoreUpload(ctx, Upload{ID: "u2", SHA256: "abc", UploadedAt: newer.UploadedAt})
+	store.StoreUpload(ctx, Upload{ID: "u3", SHA256: "def", UploadedAt: newer.UploadedAt})
+	uploads, err := store.FindUploadsBySHA256(ctx, "abc")
+	if err != nil || len(uploads) != 2 || uploads[0].ID != "u2" || uploads[1].ID != "u1" {
+		t.Errorf("Expected uploads u2, u1, got %+v (err %v)", uploads, err)
+	}
+	if uploads, _ := store.FindUploadsBySHA256(ctx, "fed"); uploads == nil || len(uploads) != 0 {
+		t.Errorf("Expected an empty slice, got %#v", uploads)
+	}
+}
+
+// TestSQLiteStore_NormalizedReads checks that ranking and kill matrices computed from the normalized tables
+// match what the reporter computes from the full reports, for rows written directly and filled in by migration 6.
+func TestSQLiteStore_NormalizedReads(t *testing.T) {
+	store, path := openTestSQLiteStore(t)
+	ctx := context.Background()
+
+	logFile, err := os.Open(filepath.Join("..", "data", "games.log"))
+	if err != nil {
+		t.Fatalf("Failed to open the sample log: %v", err)
+	}
+	defer logFile.Close()
+	result, err := parser.Parse(ctx, logFile, parser.Options{})
+	if err != nil {
+		t.Fatalf("Parse returned an error: %v", err)
+	}
+	var reports []reporter.GameReport
+	for _, report := range reporter.FormatGameDataWithOptions(result.Games, reporter.Options{Source: "sample"}) {
+		reports = append(reports, report)
+	}
+	// A team game the blue team won although Zeh, on red, scored most
+	reports = append(reports, reporter.GameReport{
+		ID: "team", Players: []string{"Zeh", "Mal", "Isgalamido"}, DurationSeconds: 600,
+		Kills:    map[string]int{"Zeh": 5, "Mal": 3, "Isgalamido": 1},
+		Sessions: map[string]reporter.PlayerSessions{"Mal": {SecondsPlayed: 240}},
+		Teams: &reporter.TeamsReport{
+			Red: reporter.TeamReport{Players: []string{"Zeh"}}, Blue: reporter.TeamReport{Players: []string{"Mal", "Isgalamido"}}, Winner: "blue",
+		},
+	})
+	if _, err := store.StoreGameReports(ctx, reports); err != nil {
+		t.Fatalf("StoreGameReports returned an error: %v", err)
+	}
+
+	check := func(store *SQLiteStore) {
+		t.Helper()
+		for _, metric := range []reporter.RankingMetric{reporter.MetricScore, reporter.MetricKills, reporter.MetricKDRatio, reporter.MetricKillsPerMinute, reporter.MetricWins} {
+			opts := reporter.RankingOptions{Metric: metric, MinGames: 2}
+			got, err := store.RankPlayers(ctx, opts)
+			if err != nil {
+				t.Fatalf("RankPlayers returned an error: %v", err)
+			}
+			if want := reporter.RankPlayers(reports, opts); !reflect.DeepEqual(got, want) {
+				t.Errorf("Ranking by %s: expected %+v, got %+v", metric, want, got)
+			}
+		}
+		for _, report := range reports {
+			got, err := store.GetKillMatrix(ctx, report.ID)
+			if err != nil {
+				t.Fatalf("GetKillMatrix returned an error: %v", err)
+			}
+			if want := killMatrix(report); !reflect.DeepEqual(got, want) {
+				t.Errorf("Kill matrix of game %d: expected %v, got %v", report.Ordinal, want, got)
+			}
+		}
+	}
+	check(store)
+	if matrix, err := store.GetKillMatrix(ctx, "missing"); matrix != nil || err != nil {
+		t.Errorf("Expected no kill matrix for a missing game, got %v (err %v)", matrix, err)
+	}
+
+	// Undo migration 6 and let reopening apply it to the stored games
+	store.db.Exec(`ALTER TABLE game_players DROP COLUMN seconds_played`)
+	store.db.Exec(`ALTER TABLE game_players DROP COLUMN won`)
+	store.db.Exec(`DELETE FROM schema_migrations WHERE version = 6`)
+	store.Close()
+	reopened, err := OpenSQLiteStore(ctx, path)
+	if err != nil {
+		t.Fatalf("Reopening returned an error: %v", err)
+	}
+	defer reopened.Close()
+	check(reopened)
+}
+
+func TestGetKillMatrix_SameAcrossStores(t *testing.T) {
+	ctx := context.Background()
+	// Slot 2 kills slot 3 and then takes its name, so both end the game as B
+	log := `  0:00 InitGame: \mapname\q3dm17
+  0:01 ClientUserinfoChanged: 2 n\A\t\0\model\sarge
+  0:01 ClientUserinfoChanged: 3 n\B\t\0\model\sarge
+  0:01 ClientUserinfoChanged: 4 n\C\t\0\model\sarge
+  0:05 Kill: 2 3 10: A killed B by MOD_RAILGUN
+  0:06 Kill: 4 3 10: C killed B by MOD_RAILGUN
+  0:07 ClientUserinfoChanged: 2 n\B\t\0\model\sarge
+  0:08 Kill: 2 4 10: B killed C by MOD_RAILGUN
+  0:10 ShutdownGame:
+`
+	result, err := parser.Parse(ctx, strings.NewReader(log), parser.Options{})
+	if err != nil {
+		t.Fatalf("Parse returned an error: %v", err)
+	}
+	var reports []reporter.GameReport
+	for _, report := range reporter.FormatGameDataWithOptions(result.Games, reporter.Options{Source: "same-name"}) {
+		reports = append(reports, report)
+	}
+	// Stored before same-named slots were left out of the matrix
+	legacy := sqliteTestReport("legacy")
+	legacy.KillMatrix = map[string]map[string]int{"Isgalamido": {"Isgalamido": 1, "Dono da Bola": 2}}
+	reports = append(reports, legacy)
+
+	sqliteStore, _ := openTestSQLiteStore(t)
+	stores := map[string]GameStore{"memory": NewMemoryStore(), "sqlite": sqliteStore}
+	expected := map[string]map[string]map[string]int{
+		reports[0].ID: {"B": {"C": 1}, "C": {"B": 1}},
+		"legacy":      {"Isgalamido": {"Dono da Bola": 2}},
+	}
+	for name, store := range stores {
+		if _, err := store.StoreGameReports(ctx, reports); err != nil {
+			t.Fatalf("%s: StoreGameReports returned an error: %v", name, err)
+		}
+		for id, want := range expected {
+			got, err := store.GetKillMatrix(ctx, id)
+			if err != nil {
+				t.Fatalf("%s: GetKillMatrix returned an error: %v", name, err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: kill matrix of game %s: expected %v, got %v", name, id, want, got)
+			}
+		}
+	}
+	if !reflect.DeepEqual(reports[0].KillMatrix, expected[reports[0].ID]) {
+		t.Errorf("Expected the report to hold the same matrix, got %v", reports[0].KillMatrix)
+	}
+}
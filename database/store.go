@@ -0,0 +1,297 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"quake_log_parser/rating"
+	"quake_log_parser/reporter"
+)
+
+// GameStore persists game reports and the player ratings derived from them.
+// The API only talks to storage through this interface.
+type GameStore interface {
+	// StoreGameReports stores the reports whose ID is not stored yet and leaves the stored copy of the others alone,
+	// so storing the same games twice changes nothing. It returns the IDs it added, in the order given.
+	StoreGameReports(ctx context.Context, reports []reporter.GameReport) ([]string, error)
+	// GetGameReport returns (nil, nil) when no game has gameID.
+	GetGameReport(ctx context.Context, gameID string) (*reporter.GameReport, error)
+	// FindGameReports returns the reports matching filter in the order they were stored (see sortGameReports).
+	// It never returns a nil slice.
+	FindGameReports(ctx context.Context, filter GameFilter) ([]reporter.GameReport, error)
+	// FindGameIDsByHash maps each of hashes that a stored game has (see reporter.GameReport.Hash)
+	// to the ID of the first such game in stored order.
+	FindGameIDsByHash(ctx context.Context, hashes []string) (map[string]string, error)
+	// DeleteGameReport reports whether a game with gameID existed.
+	DeleteGameReport(ctx context.Context, gameID string) (bool, error)
+	DeleteAllGameReports(ctx context.Context) error
+
+	// RankPlayers aggregates every stored game into a player ranking.
+	RankPlayers(ctx context.Context, opts reporter.RankingOptions) ([]reporter.PlayerRankEntry, error)
+	// GetKillMatrix returns killer -> victim -> times killed for a game, empty when nobody killed anybody.
+	// It returns (nil, nil) when no game has gameID.
+	GetKillMatrix(ctx context.Context, gameID string) (map[string]map[string]int, error)
+
+	// ReplaceRatings replaces every stored player rating.
+	ReplaceRatings(ctx context.Context, ratings []rating.PlayerRating) error
+	// GetRatings returns every stored rating, best first.
+	GetRatings(ctx context.Context) ([]rating.PlayerRating, error)
+	// GetRating returns (nil, nil) when the player has no rating.
+	GetRating(ctx context.Context, name string) (*rating.PlayerRating, error)
+
+	// StoreUpload records an upload. Its games are stored separately, with their UploadID set.
+	StoreUpload(ctx context.Context, upload Upload) error
+	// GetUpload returns (nil, nil) when no upload has uploadID.
+	GetUpload(ctx context.Context, uploadID string) (*Upload, error)
+	// ListUploads returns every upload, newest first. It never returns a nil slice.
+	ListUploads(ctx context.Context) ([]Upload, error)
+	// FindUploadsBySHA256 returns the uploads of files with that SHA-256, newest first. It never returns a nil slice.
+	FindUploadsBySHA256(ctx context.Context, sha256 string) ([]Upload, error)
+	// DeleteUpload deletes an upload and the games whose UploadID is uploadID.
+	// It reports whether the upload existed and how many games were deleted.
+	DeleteUpload(ctx context.Context, uploadID string) (bool, int, error)
+}
+
+// Storage backends accepted by the STORE_BACKEND environment variable.
+const (
+	BackendMongo  = "mongo"  // The default
+	BackendMemory = "memory" // Nothing survives a restart; meant for tests and small deployments
+	BackendSQLite = "sqlite" // A single file, see SQLITE_PATH; no database server needed
+)
+
+// BackendFromEnv returns the storage backend selected by STORE_BACKEND, BackendMongo when unset.
+func BackendFromEnv() (string, error) {
+	switch backend := strings.ToLower(strings.TrimSpace(os.Getenv("STORE_BACKEND"))); backend {
+	case "", BackendMongo:
+		return BackendMongo, nil
+	case BackendMemory, BackendSQLite:
+		return backend, nil
+	default:
+		return "", fmt.Errorf("unknown STORE_BACKEND %q (expected %q, %q or %q)", backend, BackendMongo, BackendMemory, BackendSQLite)
+	}
+}
+
+// RefreshRatings recomputes every player's rating from the stored games, in the order they were stored, and stores the result.
+func RefreshRatings(ctx context.Context, store GameStore) error {
+	reports, err := store.FindGameReports(ctx, GameFilter{})
+	if err != nil {
+		return err
+	}
+	return store.ReplaceRatings(ctx, rating.Compute(reports, rating.DefaultConfig))
+}
+
+// What happened to each game of an upload, see GameOutcome.
+const (
+	GameNew       = "new"       // Stored by this upload
+	GameDuplicate = "duplicate" // Same lines as a game stored before or earlier in the same file
+	GameSkipped   = "skipped"   // Not looked at, because the same file from the same source was uploaded before and its games are still stored
+)
+
+// GameOutcome tells what an upload did with one of its games.
+type GameOutcome struct {
+	ID          string `json:"id"`
+	Ordinal     int    `json:"ordinal"`
+	Status      string `json:"status"`                 // GameNew, GameDuplicate or GameSkipped
+	DuplicateOf string `json:"duplicate_of,omitempty"` // For duplicates and skipped games, the ID of the stored game with the same lines
+}
+
+// StoreNewGameReports stores the reports whose lines are not stored yet under any ID, using the game hash as a fingerprint,
+// so the same game uploaded under another source is not stored twice. Reports without a hash are only matched by ID.
+// It returns an outcome per report, in the order given.
+func StoreNewGameReports(ctx context.Context, store GameStore, reports []reporter.GameReport) ([]GameOutcome, error) {
+	hashes := make([]string, 0, len(reports))
+	for _, report := range reports {
+		if report.Hash != "" {
+			hashes = append(hashes, report.Hash)
+		}
+	}
+	known, err := store.FindGameIDsByHash(ctx, hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	outcomes := make([]GameOutcome, len(reports))
+	var fresh []reporter.GameReport
+	for i, report := range reports {
+		outcomes[i] = GameOutcome{ID: report.ID, Ordinal: report.Ordinal, Status: GameNew}
+		if existing, ok := known[report.Hash]; ok && report.Hash != "" {
+			outcomes[i].Status = GameDuplicate
+			outcomes[i].DuplicateOf = existing
+			continue
+		}
+		if report.Hash != "" {
+			known[report.Hash] = report.ID // Later copies in the same file are duplicates of this one
+		}
+		fresh = append(fresh, report)
+	}
+
+	storedIDs, err := store.StoreGameReports(ctx, fresh)
+	if err != nil {
+		return nil, err
+	}
+	stored := make(map[string]bool, len(storedIDs))
+	for _, id := range storedIDs {
+		stored[id] = true
+	}
+	for i := range outcomes {
+		// A game with the same ID can only be the same game, e.g. one stored before it had a hash
+		if outcomes[i].Status == GameNew && !stored[outcomes[i].ID] {
+			outcomes[i].Status = GameDuplicate
+			outcomes[i].DuplicateOf = outcomes[i].ID
+		}
+	}
+	return outcomes, nil
+}
+
+// SkipStoredGameReports returns skipped outcomes for reports, in the order given, if the lines of every one of them are still stored.
+// Otherwise, e.g. after some games were deleted, it returns nil so the reports get stored again.
+func SkipStoredGameReports(ctx context.Context, store GameStore, reports []reporter.GameReport) ([]GameOutcome, error) {
+	hashes := make([]string, len(reports))
+	for i, report := range reports {
+		hashes[i] = report.Hash
+	}
+	known, err := store.FindGameIDsByHash(ctx, hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	outcomes := make([]GameOutcome, len(reports))
+	for i, report := range reports {
+		existing, ok := known[report.Hash]
+		if !ok || report.Hash == "" {
+			return nil, nil
+		}
+		outcomes[i] = GameOutcome{ID: report.ID, Ordinal: report.Ordinal, Status: GameSkipped, DuplicateOf: existing}
+	}
+	return outcomes, nil
+}
+
+// killMatrix returns the kill matrix stored in report, never nil.
+// Self entries, which reports parsed before same-named slots were left out of the matrix may hold, are dropped
+// so every backend serves the matrix SQLite computes from its kills table.
+func killMatrix(report reporter.GameReport) map[string]map[string]int {
+	matrix := make(map[string]map[string]int, len(report.KillMatrix))
+	for killer, victims := range report.KillMatrix {
+		for victim, count := range victims {
+			if killer == victim {
+				continue
+			}
+			if matrix[killer] == nil {
+				matrix[killer] = make(map[string]int, len(victims))
+			}
+			matrix[killer][victim] = count
+		}
+	}
+	return matrix
+}
+
+// sortGameReports puts reports in the order they were stored: by upload time, then log and position in the log.
+// The game ID only breaks ties between games without that metadata.
+func sortGameReports(reports []reporter.GameReport) {
+	sort.Slice(reports, func(i, j int) bool {
+		a, b := reports[i], reports[j]
+		if !a.UploadedAt.Equal(b.UploadedAt) {
+			return a.UploadedAt.Before(b.UploadedAt)
+		}
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+		if a.Ordinal != b.Ordinal {
+			return a.Ordinal < b.Ordinal
+		}
+		return a.ID < b.ID
+	})
+}
+
+// MongoStore is a GameStore backed by MongoDB collections.
+type MongoStore struct {
+	games   *mongo.Collection
+	ratings *mongo.Collection
+	uploads *mongo.Collection
+}
+
+// NewMongoStore returns a store using gameCollection for game reports and the ratings and uploads collections next to it.
+func NewMongoStore(gameCollection *mongo.Collection) *MongoStore {
+	return &MongoStore{
+		games:   gameCollection,
+		ratings: GetRatingsCollection(gameCollection),
+		uploads: GetUploadsCollection(gameCollection),
+	}
+}
+
+func (s *MongoStore) StoreGameReports(ctx context.Context, reports []reporter.GameReport) ([]string, error) {
+	return StoreGameReports(ctx, s.games, reports)
+}
+
+func (s *MongoStore) GetGameReport(ctx context.Context, gameID string) (*reporter.GameReport, error) {
+	return GetGameReportByID(ctx, s.games, gameID)
+}
+
+func (s *MongoStore) FindGameReports(ctx context.Context, filter GameFilter) ([]reporter.GameReport, error) {
+	return FindGameReports(ctx, s.games, filter)
+}
+
+func (s *MongoStore) FindGameIDsByHash(ctx context.Context, hashes []string) (map[string]string, error) {
+	return FindGameIDsByHash(ctx, s.games, hashes)
+}
+
+func (s *MongoStore) DeleteGameReport(ctx context.Context, gameID string) (bool, error) {
+	deleted, err := DeleteGameReportByID(ctx, s.games, gameID)
+	return deleted > 0, err
+}
+
+func (s *MongoStore) DeleteAllGameReports(ctx context.Context) error {
+	return DeleteAllGameReportsFromDB(ctx, s.games)
+}
+
+func (s *MongoStore) RankPlayers(ctx context.Context, opts reporter.RankingOptions) ([]reporter.PlayerRankEntry, error) {
+	reports, err := GetAllGameReports(ctx, s.games)
+	if err != nil {
+		return nil, err
+	}
+	return reporter.RankPlayers(reports, opts), nil
+}
+
+func (s *MongoStore) GetKillMatrix(ctx context.Context, gameID string) (map[string]map[string]int, error) {
+	report, err := GetGameReportByID(ctx, s.games, gameID)
+	if err != nil || report == nil {
+		return nil, err
+	}
+	return killMatrix(*report), nil
+}
+
+func (s *MongoStore) ReplaceRatings(ctx context.Context, ratings []rating.PlayerRating) error {
+	return ReplaceRatings(ctx, s.ratings, ratings)
+}
+
+func (s *MongoStore) GetRatings(ctx context.Context) ([]rating.PlayerRating, error) {
+	return GetAllRatings(ctx, s.ratings)
+}
+
+func (s *MongoStore) GetRating(ctx context.Context, name string) (*rating.PlayerRating, error) {
+	return GetRatingByPlayer(ctx, s.ratings, name)
+}
+
+func (s *MongoStore) StoreUpload(ctx context.Context, upload Upload) error {
+	return StoreUpload(ctx, s.uploads, upload)
+}
+
+func (s *MongoStore) GetUpload(ctx context.Context, uploadID string) (*Upload, error) {
+	return GetUploadByID(ctx, s.uploads, uploadID)
+}
+
+func (s *MongoStore) ListUploads(ctx context.Context) ([]Upload, error) {
+	return GetAllUploads(ctx, s.uploads)
+}
+
+func (s *MongoStore) FindUploadsBySHA256(ctx context.Context, sha256 string) ([]Upload, error) {
+	return FindUploadsBySHA256(ctx, s.uploads, sha256)
+}
+
+func (s *MongoStore) DeleteUpload(ctx context.Context, uploadID string) (bool, int, error) {
+	return DeleteUploadByID(ctx, s.uploads, s.games, uploadID)
+}
@@ -0,0 +1,140 @@
+package database
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const defaultUploadsCollection = "uploads"
+
+// Upload records one log file sent to POST /games/upload, so bad imports can be audited and rolled back.
+type Upload struct {
+	ID            string    `json:"id" bson:"_id"` // See NewUploadID
+	Filename      string    `json:"filename" bson:"filename"`
+	Size          int64     `json:"size" bson:"size"`         // Bytes
+	SHA256        string    `json:"sha256" bson:"sha256"`     // Of the whole file
+	Source        string    `json:"source" bson:"source"`     // Log source the game IDs were built from
+	Uploader      string    `json:"uploader" bson:"uploader"` // As given by the client, not authenticated
+	ClientIP      string    `json:"client_ip" bson:"client_ip"`
+	UploadedAt    time.Time `json:"uploaded_at" bson:"uploaded_at"`
+	ParserVersion string    `json:"parser_version" bson:"parser_version"` // parser.Version that read the file
+	ParseMode     string    `json:"parse_mode" bson:"parse_mode"`
+	GameIDs       []string  `json:"game_ids" bson:"game_ids"` // Every game in the file, in log order, whether or not this upload stored it
+}
+
+var uploadIDPattern = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// NewUploadID returns a random upload ID: 32 lowercase hex digits.
+func NewUploadID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("failed to generate upload ID: %w", err)
+	}
+	return hex.EncodeToString(b[:]), nil
+}
+
+// ValidUploadID reports whether id has the format of NewUploadID.
+func ValidUploadID(id string) bool {
+	return uploadIDPattern.MatchString(id)
+}
+
+// GetUploadsCollection returns the collection holding uploads, in the same database as gameCollection.
+func GetUploadsCollection(gameCollection *mongo.Collection) *mongo.Collection {
+	return gameCollection.Database().Collection(defaultUploadsCollection)
+}
+
+// StoreUpload inserts an upload record.
+func StoreUpload(ctx context.Context, collection *mongo.Collection, upload Upload) error {
+	if collection == nil {
+		return fmt.Errorf("MongoDB collection is nil")
+	}
+	if _, err := collection.InsertOne(ctx, upload); err != nil {
+		return fmt.Errorf("failed to store upload %s: %w", upload.ID, err)
+	}
+	return nil
+}
+
+// GetUploadByID retrieves an upload record.
+// It returns (nil, nil) if no upload has that ID.
+func GetUploadByID(ctx context.Context, collection *mongo.Collection, uploadID string) (*Upload, error) {
+	if collection == nil {
+		return nil, fmt.Errorf("MongoDB collection is nil")
+	}
+
+	var upload Upload
+	err := collection.FindOne(ctx, bson.M{"_id": uploadID}).Decode(&upload)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil // Not found
+		}
+		return nil, fmt.Errorf("failed to find or decode upload %s: %w", uploadID, err)
+	}
+	return &upload, nil
+}
+
+// GetAllUploads retrieves every upload record, newest first.
+func GetAllUploads(ctx context.Context, collection *mongo.Collection) ([]Upload, error) {
+	if collection == nil {
+		return nil, fmt.Errorf("MongoDB collection is nil")
+	}
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "uploaded_at", Value: -1}, {Key: "_id", Value: 1}})
+	cursor, err := collection.Find(ctx, bson.D{}, findOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find uploads: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	uploads := []Upload{}
+	if err = cursor.All(ctx, &uploads); err != nil {
+		return nil, fmt.Errorf("failed to decode uploads: %w", err)
+	}
+	return uploads, nil
+}
+
+// FindUploadsBySHA256 retrieves the uploads of files with the given SHA-256, newest first.
+func FindUploadsBySHA256(ctx context.Context, collection *mongo.Collection, sha256 string) ([]Upload, error) {
+	if collection == nil {
+		return nil, fmt.Errorf("MongoDB collection is nil")
+	}
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "uploaded_at", Value: -1}, {Key: "_id", Value: 1}})
+	cursor, err := collection.Find(ctx, bson.M{"sha256": sha256}, findOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find uploads by SHA-256: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	uploads := []Upload{}
+	if err = cursor.All(ctx, &uploads); err != nil {
+		return nil, fmt.Errorf("failed to decode uploads: %w", err)
+	}
+	return uploads, nil
+}
+
+// DeleteUploadByID deletes an upload record and the games it stored from gameCollection.
+// It returns whether the upload existed and how many games were deleted.
+func DeleteUploadByID(ctx context.Context, collection, gameCollection *mongo.Collection, uploadID string) (bool, int, error) {
+	if collection == nil || gameCollection == nil {
+		return false, 0, fmt.Errorf("MongoDB collection is nil")
+	}
+
+	// Games first: if this fails the upload is still there to retry the delete
+	games, err := gameCollection.DeleteMany(ctx, bson.M{"upload_id": uploadID})
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to delete games of upload %s: %w", uploadID, err)
+	}
+	result, err := collection.DeleteOne(ctx, bson.M{"_id": uploadID})
+	if err != nil {
+		return false, int(games.DeletedCount), fmt.Errorf("failed to delete upload %s: %w", uploadID, err)
+	}
+	return result.DeletedCount > 0, int(games.DeletedCount), nil
+}
@@ -26,7 +26,7 @@ const docTemplate = `{
     "paths": {
         "/games": {
             "get": {
-                "description": "Retrieves a list of all game reports stored in the database, sorted by game ID.",
+                "description": "Retrieves a list of all game reports stored in the database, in the order they were stored (by upload time, then source and position in the log). Optional query parameters filter on how the game ended.",
                 "consumes": [
                     "application/json"
                 ],
@@ -37,6 +37,38 @@ const docTemplate = `{
                     "games"
                 ],
                 "summary": "Get all game reports",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Case-insensitive part of the exit reason (e.g. fraglimit, timelimit, capturelimit)",
+                        "name": "exit_reason",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only games that ended without (true) or with (false) an Exit line",
+                        "name": "aborted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum game duration in seconds",
+                        "name": "min_duration",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum game duration in seconds",
+                        "name": "max_duration",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only games first stored by this upload",
+                        "name": "upload_id",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "Successfully retrieved list of game reports",
@@ -47,6 +79,12 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "400": {
+                        "description": "Invalid filter parameter",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Failed to retrieve game reports",
                         "schema": {
@@ -85,7 +123,7 @@ const docTemplate = `{
         },
         "/games/upload": {
             "post": {
-                "description": "Uploads a game log file (.log). The server parses it, generates game reports, and stores them.",
+                "description": "Uploads a game log file (.log). The server parses it, generates game reports, and stores them.\nEach game's ID is a hash of the log source, its position in the log and its lines, so uploading the same log again stores nothing new.\nEvery upload is recorded (see /uploads) and owns the games it stored first.\nA file with the same SHA-256 and source as an earlier upload is not processed again while its games are still stored: they are reported as skipped, unless force is set.\nOtherwise each game is new, or a duplicate of a stored game (or of an earlier game in the file) with the same lines, whatever its source.",
                 "consumes": [
                     "multipart/form-data"
                 ],
@@ -103,17 +141,51 @@ const docTemplate = `{
                         "name": "logFile",
                         "in": "formData",
                         "required": true
+                    },
+                    {
+                        "enum": [
+                            "lenient",
+                            "strict"
+                        ],
+                        "type": "string",
+                        "description": "Parse mode: lenient (default) keeps going and reports diagnostics, strict rejects the file on its first anomaly",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Longest gap between two kills of a multi-kill, as a Go duration (default 3s)",
+                        "name": "multikill_window",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Name of the log the games come from, e.g. a server name, so a growing log keeps its game IDs (default: the SHA-256 of the file)",
+                        "name": "source",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Who is uploading the file, recorded with the upload",
+                        "name": "uploader",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Check the games of a file uploaded before instead of skipping them",
+                        "name": "force",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Log file processed. No games found to report (already OK if file is valid but empty of games)",
+                        "description": "Log file processed, nothing new stored: no games, only duplicates, or a file uploaded before",
                         "schema": {
                             "$ref": "#/definitions/main.UploadResponse"
                         }
                     },
                     "201": {
-                        "description": "Log file processed and game(s) stored successfully",
+                        "description": "Log file processed and new game(s) stored successfully, with what happened to each game and any parse diagnostics",
                         "schema": {
                             "$ref": "#/definitions/main.UploadResponse"
                         }
@@ -124,6 +196,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.ErrorResponse"
                         }
                     },
+                    "422": {
+                        "description": "Strict mode: the log contains an anomaly",
+                        "schema": {
+                            "$ref": "#/definitions/main.StrictModeErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Server error during file processing or storage",
                         "schema": {
@@ -135,7 +213,7 @@ const docTemplate = `{
         },
         "/games/{id}": {
             "get": {
-                "description": "Retrieves full details for a specific game based on its unique ID.",
+                "description": "Retrieves full details for a specific game based on its unique ID. Awards are recomputed from the kill log when multikill_window is given; otherwise they use the window the game was uploaded with.",
                 "consumes": [
                     "application/json"
                 ],
@@ -148,11 +226,17 @@ const docTemplate = `{
                 "summary": "Get a single game report by its ID",
                 "parameters": [
                     {
-                        "type": "integer",
+                        "type": "string",
                         "description": "Game ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Longest gap between two kills of a multi-kill, as a Go duration (default: the upload's window)",
+                        "name": "multikill_window",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -163,7 +247,7 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Invalid game ID format",
+                        "description": "Invalid game ID format or multikill_window",
                         "schema": {
                             "$ref": "#/definitions/main.ErrorResponse"
                         }
@@ -196,7 +280,7 @@ const docTemplate = `{
                 "summary": "Delete a specific game report by its ID",
                 "parameters": [
                     {
-                        "type": "integer",
+                        "type": "string",
                         "description": "Game ID",
                         "name": "id",
                         "in": "path",
@@ -231,9 +315,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/playersranking": {
+        "/games/{id}/chat": {
             "get": {
-                "description": "Retrieves a list of players ranked by their total kills across all recorded games.",
+                "description": "Lists the say lines of a game in log order, with timestamp, client ID and the resolved player name.",
                 "consumes": [
                     "application/json"
                 ],
@@ -241,94 +325,1681 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "rankings"
+                    "games"
+                ],
+                "summary": "Get the chat log of a game",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Game ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only messages whose text contains this string (case-insensitive)",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only messages sent by this player (final or in-message name)",
+                        "name": "player",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Get aggregated player rankings across all games",
                 "responses": {
                     "200": {
-                        "description": "Successfully retrieved player rankings",
+                        "description": "Successfully retrieved chat messages",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/reporter.PlayerRankEntry"
+                                "$ref": "#/definitions/reporter.ChatMessage"
                             }
                         }
                     },
+                    "400": {
+                        "description": "Invalid game ID format",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Game not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
                     "500": {
-                        "description": "Failed to retrieve player rankings",
+                        "description": "Failed to retrieve game data",
                         "schema": {
                             "$ref": "#/definitions/main.ErrorResponse"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "main.ErrorResponse": {
-            "type": "object",
-            "properties": {
-                "error": {
-                    "type": "string"
-                }
-            }
         },
-        "main.SuccessResponse": {
-            "type": "object",
-            "properties": {
-                "message": {
-                    "type": "string"
+        "/games/{id}/diagnostics": {
+            "get": {
+                "description": "Lists the anomalies (unterminated game, unknown client, unparseable line, ...) found while parsing a game, in log order.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "games"
+                ],
+                "summary": "Get the parse diagnostics of a game",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Game ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only diagnostics of this severity (warning, error)",
+                        "name": "severity",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved game diagnostics",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/reporter.Diagnostic"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid game ID format",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Game not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to retrieve game data",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
                 }
             }
         },
-        "main.UploadResponse": {
-            "type": "object",
-            "properties": {
-                "games_processed": {
-                    "type": "integer"
-                },
-                "message": {
-                    "type": "string"
+        "/games/{id}/items": {
+            "get": {
+                "description": "Counts the items picked up in a game by class (weapon, ammo, armor, health, powerup, holdable, flag) and by item, for the whole game and per player.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "games"
+                ],
+                "summary": "Get the item pickups of a game",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Game ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved item pickups",
+                        "schema": {
+                            "$ref": "#/definitions/reporter.ItemsReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid game ID format",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Game not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to retrieve game data",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
                 }
             }
         },
-        "reporter.GameReport": {
-            "type": "object",
-            "properties": {
-                "id": {
-                    "type": "integer"
-                },
-                "kills": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "integer"
-                    }
-                },
-                "kills_by_means": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "integer"
+        "/games/{id}/matrix": {
+            "get": {
+                "description": "Returns how many times each player killed each other player, with every player's favourite victim and nemesis (the player who killed them most).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "games"
+                ],
+                "summary": "Get the who-killed-whom matrix of a game",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Game ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
-                },
-                "players": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved kill matrix",
+                        "schema": {
+                            "$ref": "#/definitions/reporter.KillMatrixReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid game ID format",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Game not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to retrieve game data",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
                     }
-                },
-                "total_kills": {
-                    "type": "integer"
                 }
             }
         },
-        "reporter.PlayerRankEntry": {
-            "type": "object",
-            "properties": {
-                "player_name": {
-                    "type": "string"
-                },
-                "total_kills": {
-                    "type": "integer"
+        "/games/{id}/timeline": {
+            "get": {
+                "description": "Groups a game's kills into time buckets, with kills by player and by means in each bucket and every player's cumulative net score at the end of each bucket.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "games"
+                ],
+                "summary": "Get the kill timeline of a game",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Game ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bucket size as a Go duration in whole seconds (default 30s)",
+                        "name": "bucket",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully built the timeline",
+                        "schema": {
+                            "$ref": "#/definitions/reporter.Timeline"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid game ID format or bucket size",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Game not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to retrieve game data",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/players/{name}/rating-history": {
+            "get": {
+                "description": "Returns a player's current Elo rating with their rating after every rated game, oldest first.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ratings"
+                ],
+                "summary": "Get a player's rating history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Player name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved rating history",
+                        "schema": {
+                            "$ref": "#/definitions/rating.PlayerRating"
+                        }
+                    },
+                    "404": {
+                        "description": "Player has no rating",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to retrieve rating history",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/players/{name}/weapons": {
+            "get": {
+                "description": "Sums a player's kills and deaths by means of death over every stored game, with the weapon they killed most with and the one they died to most.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "players"
+                ],
+                "summary": "Get a player's kills and deaths by weapon",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Player name, as they ended each game",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved weapon breakdown",
+                        "schema": {
+                            "$ref": "#/definitions/reporter.PlayerWeapons"
+                        }
+                    },
+                    "404": {
+                        "description": "Player not found in any game",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to retrieve data for the weapon breakdown",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/playersranking": {
+            "get": {
+                "description": "Ranks players across all recorded games by the selected metric. Ties are broken by net score, kills, fewest deaths, most games and then name, so the order is stable; players with the same metric value share a rank.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rankings"
+                ],
+                "summary": "Get aggregated player rankings across all games",
+                "parameters": [
+                    {
+                        "enum": [
+                            "score",
+                            "kills",
+                            "kd",
+                            "kpm",
+                            "wins"
+                        ],
+                        "type": "string",
+                        "description": "Ranking metric: score (net kills, default), kills, kd, kpm (kills per minute) or wins",
+                        "name": "metric",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only rank players with at least this many games",
+                        "name": "min_games",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved player rankings",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/reporter.PlayerRankEntry"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid metric or min_games",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to retrieve player rankings",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ratings": {
+            "get": {
+                "description": "Lists every player's Elo rating, best first. Ratings are recomputed by replaying all stored games in the order they were stored whenever games are uploaded or deleted; each game counts final placement by net score and every kill of an opponent as a duel (suicides and teamkills are not duels).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ratings"
+                ],
+                "summary": "Get the skill ratings of all players",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Only players with at least this many rated games",
+                        "name": "min_games",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved ratings (history omitted)",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/rating.PlayerRating"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid min_games",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to retrieve ratings",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/uploads": {
+            "get": {
+                "description": "Returns every recorded upload, newest first: file name, size and SHA-256, who uploaded it, the parser version and the games in it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "uploads"
+                ],
+                "summary": "List uploaded log files",
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved uploads",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/database.Upload"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to retrieve uploads",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/uploads/{id}": {
+            "get": {
+                "description": "Returns an upload with the IDs of the games it stored first, which are the games deleting it removes.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "uploads"
+                ],
+                "summary": "Get a single upload by its ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Upload ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved upload",
+                        "schema": {
+                            "$ref": "#/definitions/main.UploadDetails"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid upload ID format",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Upload not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to retrieve upload",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes an upload together with the games it stored first. Games it only found already stored are kept.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "uploads"
+                ],
+                "summary": "Roll back an upload",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Upload ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Upload and its games deleted successfully",
+                        "schema": {
+                            "$ref": "#/definitions/main.DeleteUploadResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid upload ID format",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Upload not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete upload",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "database.GameOutcome": {
+            "type": "object",
+            "properties": {
+                "duplicate_of": {
+                    "description": "For duplicates and skipped games, the ID of the stored game with the same lines",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "ordinal": {
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "GameNew, GameDuplicate or GameSkipped",
+                    "type": "string"
+                }
+            }
+        },
+        "database.Upload": {
+            "type": "object",
+            "properties": {
+                "client_ip": {
+                    "type": "string"
+                },
+                "filename": {
+                    "type": "string"
+                },
+                "game_ids": {
+                    "description": "Every game in the file, in log order, whether or not this upload stored it",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "description": "See NewUploadID",
+                    "type": "string"
+                },
+                "parse_mode": {
+                    "type": "string"
+                },
+                "parser_version": {
+                    "description": "parser.Version that read the file",
+                    "type": "string"
+                },
+                "sha256": {
+                    "description": "Of the whole file",
+                    "type": "string"
+                },
+                "size": {
+                    "description": "Bytes",
+                    "type": "integer"
+                },
+                "source": {
+                    "description": "Log source the game IDs were built from",
+                    "type": "string"
+                },
+                "uploaded_at": {
+                    "type": "string"
+                },
+                "uploader": {
+                    "description": "As given by the client, not authenticated",
+                    "type": "string"
+                }
+            }
+        },
+        "main.DeleteUploadResponse": {
+            "type": "object",
+            "properties": {
+                "games_deleted": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.StrictModeErrorResponse": {
+            "type": "object",
+            "properties": {
+                "diagnostic": {
+                    "description": "The anomaly that aborted parsing",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/reporter.Diagnostic"
+                        }
+                    ]
+                },
+                "error": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.SuccessResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.UploadDetails": {
+            "type": "object",
+            "properties": {
+                "client_ip": {
+                    "type": "string"
+                },
+                "filename": {
+                    "type": "string"
+                },
+                "game_ids": {
+                    "description": "Every game in the file, in log order, whether or not this upload stored it",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "description": "See NewUploadID",
+                    "type": "string"
+                },
+                "parse_mode": {
+                    "type": "string"
+                },
+                "parser_version": {
+                    "description": "parser.Version that read the file",
+                    "type": "string"
+                },
+                "sha256": {
+                    "description": "Of the whole file",
+                    "type": "string"
+                },
+                "size": {
+                    "description": "Bytes",
+                    "type": "integer"
+                },
+                "source": {
+                    "description": "Log source the game IDs were built from",
+                    "type": "string"
+                },
+                "stored_game_ids": {
+                    "description": "Deleting the upload deletes these games",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "uploaded_at": {
+                    "type": "string"
+                },
+                "uploader": {
+                    "description": "As given by the client, not authenticated",
+                    "type": "string"
+                }
+            }
+        },
+        "main.UploadResponse": {
+            "type": "object",
+            "properties": {
+                "diagnostics": {
+                    "description": "Anomalies found while parsing the file",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/reporter.Diagnostic"
+                    }
+                },
+                "duplicate_of_upload": {
+                    "description": "Latest earlier upload of a file with the same SHA-256 and source, if any",
+                    "type": "string"
+                },
+                "game_ids": {
+                    "description": "ID of every game in the file, in log order",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "games": {
+                    "description": "What happened to every game in the file, in log order",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.GameOutcome"
+                    }
+                },
+                "games_duplicate": {
+                    "description": "Games with the same lines as a stored game",
+                    "type": "integer"
+                },
+                "games_processed": {
+                    "type": "integer"
+                },
+                "games_skipped": {
+                    "description": "Games of a file uploaded before, still stored",
+                    "type": "integer"
+                },
+                "games_stored": {
+                    "description": "New games, stored by this upload",
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "source": {
+                    "description": "Log source the game IDs were built from",
+                    "type": "string"
+                },
+                "upload_id": {
+                    "description": "See GET /uploads/{id}",
+                    "type": "string"
+                }
+            }
+        },
+        "rating.HistoryEntry": {
+            "type": "object",
+            "properties": {
+                "change": {
+                    "type": "number"
+                },
+                "game_id": {
+                    "type": "string"
+                },
+                "rating": {
+                    "type": "number"
+                }
+            }
+        },
+        "rating.PlayerRating": {
+            "type": "object",
+            "properties": {
+                "games": {
+                    "type": "integer"
+                },
+                "history": {
+                    "description": "One entry per rated game, oldest first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/rating.HistoryEntry"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "peak": {
+                    "type": "number"
+                },
+                "rating": {
+                    "type": "number"
+                }
+            }
+        },
+        "reporter.Awards": {
+            "type": "object",
+            "properties": {
+                "ended_streaks": {
+                    "description": "Streaks of at least MinEndedStreak kills, in log order",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/reporter.EndedStreak"
+                    }
+                },
+                "first_blood": {
+                    "description": "First player killed by another player",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/reporter.FirstBlood"
+                        }
+                    ]
+                },
+                "longest_streaks": {
+                    "description": "Player name -\u003e most kills in a row without dying",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "multi_kills": {
+                    "description": "In order of their first kill",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/reporter.MultiKill"
+                    }
+                }
+            }
+        },
+        "reporter.ChatMessage": {
+            "type": "object",
+            "properties": {
+                "client_id": {
+                    "description": "-1 when the speaker could not be matched to a client slot",
+                    "type": "integer"
+                },
+                "name": {
+                    "description": "Name the message was sent under",
+                    "type": "string"
+                },
+                "player": {
+                    "description": "Name the speaker ended the game with, as used in Kills",
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                },
+                "time": {
+                    "description": "m:ss, as in the log",
+                    "type": "string"
+                }
+            }
+        },
+        "reporter.Diagnostic": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "game_id": {
+                    "type": "integer"
+                },
+                "line": {
+                    "description": "0 when not tied to a line (e.g. end of log)",
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "severity": {
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "reporter.EndedStreak": {
+            "type": "object",
+            "properties": {
+                "ended_by": {
+                    "description": "Killer's name, \"\u003cworld\u003e\", or the player themselves for a suicide",
+                    "type": "string"
+                },
+                "player": {
+                    "type": "string"
+                },
+                "streak": {
+                    "type": "integer"
+                },
+                "time": {
+                    "description": "m:ss, as in the log",
+                    "type": "string"
+                }
+            }
+        },
+        "reporter.FirstBlood": {
+            "type": "object",
+            "properties": {
+                "killer": {
+                    "type": "string"
+                },
+                "time": {
+                    "description": "m:ss, as in the log",
+                    "type": "string"
+                },
+                "victim": {
+                    "type": "string"
+                }
+            }
+        },
+        "reporter.GameReport": {
+            "type": "object",
+            "properties": {
+                "aborted": {
+                    "description": "Ended without an Exit line",
+                    "type": "boolean"
+                },
+                "aliases": {
+                    "description": "Final player name -\u003e earlier names used in the game",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        }
+                    }
+                },
+                "awards": {
+                    "description": "First blood, streaks and multi-kills, nil when the game has no kills",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/reporter.Awards"
+                        }
+                    ]
+                },
+                "chat": {
+                    "description": "say lines, in log order",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/reporter.ChatMessage"
+                    }
+                },
+                "diagnostics": {
+                    "description": "Parse anomalies found in this game",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/reporter.Diagnostic"
+                    }
+                },
+                "duration_seconds": {
+                    "description": "Clock jumps in the log are not counted, see the clock_jump diagnostic",
+                    "type": "integer"
+                },
+                "end_time": {
+                    "description": "m:ss, as in the log",
+                    "type": "string"
+                },
+                "exit_reason": {
+                    "description": "e.g. \"Fraglimit hit.\"",
+                    "type": "string"
+                },
+                "frag_limit": {
+                    "type": "integer"
+                },
+                "game_type": {
+                    "type": "integer"
+                },
+                "game_type_name": {
+                    "type": "string"
+                },
+                "hash": {
+                    "description": "SHA-256 of the game's lines",
+                    "type": "string"
+                },
+                "hostname": {
+                    "type": "string"
+                },
+                "id": {
+                    "description": "See GameID",
+                    "type": "string"
+                },
+                "items": {
+                    "description": "Item pickups, nil when the game has none",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/reporter.ItemsReport"
+                        }
+                    ]
+                },
+                "kill_log": {
+                    "description": "Every kill, in log order",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/reporter.KillEntry"
+                    }
+                },
+                "kill_matrix": {
+                    "description": "Killer -\u003e victim -\u003e times killed",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "object",
+                        "additionalProperties": {
+                            "type": "integer"
+                        }
+                    }
+                },
+                "kills": {
+                    "description": "Net score: kills minus deaths by \u003cworld\u003e, suicides and teamkills",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "kills_by_category": {
+                    "description": "KillsByMeans grouped and labelled using the means of death enum",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "kills_by_means": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "map": {
+                    "description": "Server settings the game was played with, taken from its InitGame line",
+                    "type": "string"
+                },
+                "means": {
+                    "description": "Most kills first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/reporter.MeansSummary"
+                    }
+                },
+                "multikill_window": {
+                    "description": "Go duration Awards found multi-kills with",
+                    "type": "string"
+                },
+                "ordinal": {
+                    "description": "1-based position of the game in its log",
+                    "type": "integer"
+                },
+                "player_stats": {
+                    "description": "Player name -\u003e kill and death breakdown",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/reporter.PlayerStats"
+                    }
+                },
+                "players": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "scoreboard": {
+                    "description": "Server-reported final scoreboard, reconciled against the scores computed from the kill lines",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/reporter.ScoreboardEntry"
+                    }
+                },
+                "scoreboard_mismatch": {
+                    "description": "ScoreboardStatus is ScoreboardMismatch",
+                    "type": "boolean"
+                },
+                "scoreboard_status": {
+                    "description": "See the Scoreboard* constants, empty without a scoreboard",
+                    "type": "string"
+                },
+                "sessions": {
+                    "description": "Player name -\u003e connections during the game",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/reporter.PlayerSessions"
+                    }
+                },
+                "settings": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "source": {
+                    "description": "Where the game came from",
+                    "type": "string"
+                },
+                "start_time": {
+                    "description": "How and when the game ended",
+                    "type": "string"
+                },
+                "teams": {
+                    "description": "Only set for team gametypes",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/reporter.TeamsReport"
+                        }
+                    ]
+                },
+                "time_limit": {
+                    "description": "Minutes",
+                    "type": "integer"
+                },
+                "total_kills": {
+                    "type": "integer"
+                },
+                "upload_id": {
+                    "description": "Upload that first stored the game",
+                    "type": "string"
+                },
+                "uploaded_at": {
+                    "description": "When the game was first stored",
+                    "type": "string"
+                }
+            }
+        },
+        "reporter.ItemCounts": {
+            "type": "object",
+            "properties": {
+                "by_class": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "by_item": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "reporter.ItemsReport": {
+            "type": "object",
+            "properties": {
+                "by_class": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "by_item": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "players": {
+                    "description": "Player name -\u003e their pickups",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/reporter.ItemCounts"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "reporter.KillEntry": {
+            "type": "object",
+            "properties": {
+                "killer": {
+                    "description": "\"\u003cworld\u003e\" for environmental deaths",
+                    "type": "string"
+                },
+                "means": {
+                    "type": "string"
+                },
+                "seconds": {
+                    "description": "Seconds since the game started",
+                    "type": "integer"
+                },
+                "suicide": {
+                    "description": "Victim killed themselves, told apart by client slot rather than name",
+                    "type": "boolean"
+                },
+                "team_kill": {
+                    "description": "Victim was the killer's teammate",
+                    "type": "boolean"
+                },
+                "time": {
+                    "description": "m:ss, as in the log",
+                    "type": "string"
+                },
+                "victim": {
+                    "type": "string"
+                }
+            }
+        },
+        "reporter.KillMatrixReport": {
+            "type": "object",
+            "properties": {
+                "matrix": {
+                    "description": "Killer -\u003e victim -\u003e times killed",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "object",
+                        "additionalProperties": {
+                            "type": "integer"
+                        }
+                    }
+                },
+                "rivalries": {
+                    "description": "Player name -\u003e rivalry",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/reporter.Rivalry"
+                    }
+                }
+            }
+        },
+        "reporter.MeansSummary": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "description": "direct, splash, melee, environmental or other",
+                    "type": "string"
+                },
+                "kills": {
+                    "type": "integer"
+                },
+                "label": {
+                    "description": "e.g. \"Rocket Launcher (splash)\"",
+                    "type": "string"
+                },
+                "means": {
+                    "description": "MOD_* name, as in KillsByMeans",
+                    "type": "string"
+                }
+            }
+        },
+        "reporter.MultiKill": {
+            "type": "object",
+            "properties": {
+                "end": {
+                    "description": "m:ss of the last kill",
+                    "type": "string"
+                },
+                "kills": {
+                    "type": "integer"
+                },
+                "player": {
+                    "type": "string"
+                },
+                "start": {
+                    "description": "m:ss of the first kill",
+                    "type": "string"
+                }
+            }
+        },
+        "reporter.PlayerRankEntry": {
+            "type": "object",
+            "properties": {
+                "deaths": {
+                    "type": "integer"
+                },
+                "games": {
+                    "type": "integer"
+                },
+                "kd_ratio": {
+                    "type": "number"
+                },
+                "kills": {
+                    "type": "integer"
+                },
+                "kills_per_minute": {
+                    "type": "number"
+                },
+                "metric": {
+                    "$ref": "#/definitions/reporter.RankingMetric"
+                },
+                "player_name": {
+                    "type": "string"
+                },
+                "rank": {
+                    "description": "Players with the same metric value share a rank",
+                    "type": "integer"
+                },
+                "seconds_played": {
+                    "type": "integer"
+                },
+                "total_kills": {
+                    "description": "Net score summed over games",
+                    "type": "integer"
+                },
+                "value": {
+                    "description": "The player's value for Metric",
+                    "type": "number"
+                },
+                "wins": {
+                    "type": "integer"
+                }
+            }
+        },
+        "reporter.PlayerSessions": {
+            "type": "object",
+            "properties": {
+                "left_early": {
+                    "description": "The last session ended with a disconnect before the game ended",
+                    "type": "boolean"
+                },
+                "reconnects": {
+                    "type": "integer"
+                },
+                "seconds_played": {
+                    "description": "Time between ClientBegin and disconnect or game end, over all sessions",
+                    "type": "integer"
+                },
+                "sessions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/reporter.Session"
+                    }
+                }
+            }
+        },
+        "reporter.PlayerStats": {
+            "type": "object",
+            "properties": {
+                "deaths": {
+                    "description": "Deaths by any cause",
+                    "type": "integer"
+                },
+                "deaths_by_means": {
+                    "description": "Means of death -\u003e times the player died by it",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "kd_ratio": {
+                    "description": "Kills / Deaths, or Kills when the player never died",
+                    "type": "number"
+                },
+                "kills": {
+                    "description": "Other players killed",
+                    "type": "integer"
+                },
+                "kills_by_means": {
+                    "description": "Means of death -\u003e players killed with it",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "score": {
+                    "description": "Net score, same as GameReport.Kills",
+                    "type": "integer"
+                },
+                "suicides": {
+                    "description": "Deaths by the player's own weapon",
+                    "type": "integer"
+                },
+                "world_deaths": {
+                    "description": "Deaths caused by \u003cworld\u003e",
+                    "type": "integer"
+                }
+            }
+        },
+        "reporter.PlayerWeapons": {
+            "type": "object",
+            "properties": {
+                "died_most_to": {
+                    "type": "string"
+                },
+                "games": {
+                    "description": "Games the player appears in",
+                    "type": "integer"
+                },
+                "most_lethal": {
+                    "description": "Means the player killed the most players with",
+                    "type": "string"
+                },
+                "player": {
+                    "type": "string"
+                },
+                "weapons": {
+                    "description": "Most kills first, then most deaths, then by name",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/reporter.WeaponStats"
+                    }
+                }
+            }
+        },
+        "reporter.RankingMetric": {
+            "type": "string",
+            "enum": [
+                "score",
+                "kills",
+                "kd",
+                "kpm",
+                "wins"
+            ],
+            "x-enum-comments": {
+                "MetricKDRatio": "Kills / deaths over all games",
+                "MetricKills": "Other players killed",
+                "MetricKillsPerMinute": "Kills per minute played",
+                "MetricScore": "Net score summed over games, as in GameReport.Kills (the default)",
+                "MetricWins": "Games won"
+            },
+            "x-enum-varnames": [
+                "MetricScore",
+                "MetricKills",
+                "MetricKDRatio",
+                "MetricKillsPerMinute",
+                "MetricWins"
+            ]
+        },
+        "reporter.Rivalry": {
+            "type": "object",
+            "properties": {
+                "favorite_victim": {
+                    "type": "string"
+                },
+                "favorite_victim_kills": {
+                    "type": "integer"
+                },
+                "nemesis": {
+                    "type": "string"
+                },
+                "nemesis_kills": {
+                    "description": "Times the nemesis killed the player",
+                    "type": "integer"
+                }
+            }
+        },
+        "reporter.ScoreboardEntry": {
+            "type": "object",
+            "properties": {
+                "client_id": {
+                    "type": "integer"
+                },
+                "computed_score": {
+                    "description": "Net score of the player in ClientID when the scoreboard was printed",
+                    "type": "integer"
+                },
+                "difference": {
+                    "description": "ServerScore - ComputedScore",
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "ping": {
+                    "type": "integer"
+                },
+                "server_score": {
+                    "type": "integer"
+                }
+            }
+        },
+        "reporter.Session": {
+            "type": "object",
+            "properties": {
+                "begin": {
+                    "description": "Empty if the client never entered the game",
+                    "type": "string"
+                },
+                "client_id": {
+                    "type": "integer"
+                },
+                "connect": {
+                    "type": "string"
+                },
+                "disconnect": {
+                    "description": "Empty if the client was still connected at the end",
+                    "type": "string"
+                },
+                "seconds_played": {
+                    "type": "integer"
+                }
+            }
+        },
+        "reporter.TeamAssignment": {
+            "type": "object",
+            "properties": {
+                "team": {
+                    "description": "\"red\", \"blue\", \"spectator\" or \"free\"",
+                    "type": "string"
+                },
+                "time": {
+                    "description": "m:ss, as in the log",
+                    "type": "string"
+                }
+            }
+        },
+        "reporter.TeamReport": {
+            "type": "object",
+            "properties": {
+                "kills": {
+                    "description": "Enemies killed by the team's members",
+                    "type": "integer"
+                },
+                "players": {
+                    "description": "Players who ended the game on this team",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "score": {
+                    "description": "From the server's \"red:N  blue:M\" line",
+                    "type": "integer"
+                },
+                "team_kills": {
+                    "description": "Teammates killed by the team's members",
+                    "type": "integer"
+                }
+            }
+        },
+        "reporter.TeamsReport": {
+            "type": "object",
+            "properties": {
+                "blue": {
+                    "$ref": "#/definitions/reporter.TeamReport"
+                },
+                "history": {
+                    "description": "Player name -\u003e team assignments in log order",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "array",
+                        "items": {
+                            "$ref": "#/definitions/reporter.TeamAssignment"
+                        }
+                    }
+                },
+                "red": {
+                    "$ref": "#/definitions/reporter.TeamReport"
+                },
+                "winner": {
+                    "description": "\"red\", \"blue\" or \"draw\"; empty without a final team score",
+                    "type": "string"
+                }
+            }
+        },
+        "reporter.Timeline": {
+            "type": "object",
+            "properties": {
+                "bucket_seconds": {
+                    "type": "integer"
+                },
+                "buckets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/reporter.TimelineBucket"
+                    }
+                },
+                "score_lines": {
+                    "description": "Player name -\u003e net score at the end of each bucket",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "reporter.TimelineBucket": {
+            "type": "object",
+            "properties": {
+                "by_means": {
+                    "description": "Means of death -\u003e kills, every kill included",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "by_player": {
+                    "description": "Killer -\u003e other players killed, \u003cworld\u003e and suicides left out",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "end": {
+                    "description": "Seconds since the game started, exclusive",
+                    "type": "integer"
+                },
+                "kills": {
+                    "type": "integer"
+                },
+                "start": {
+                    "description": "Seconds since the game started, inclusive",
+                    "type": "integer"
+                }
+            }
+        },
+        "reporter.WeaponStats": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string"
+                },
+                "deaths": {
+                    "type": "integer"
+                },
+                "kills": {
+                    "type": "integer"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "means": {
+                    "type": "string"
                 }
             }
         }
@@ -37,34 +37,74 @@ import (
 func main() {
 	fmt.Println("Quake Log Parser API")
 
-	// Setup MongoDB connection
-	dbCtx, dbCancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer dbCancel()
-
-	// Using default URI from database pkg. Consider making this configurable via ENV for Docker.
-	mongoClient, err := database.ConnectDB(dbCtx, database.DefaultMongoDBURI) 
+	backend, err := database.BackendFromEnv()
 	if err != nil {
-		log.Fatalf("Failed to connect to MongoDB: %v", err)
+		log.Fatalf("Invalid storage configuration: %v", err)
 	}
-	defer func() {
-		disconnectCtx, cancelDisconnect := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancelDisconnect()
-		if err := mongoClient.Disconnect(disconnectCtx); err != nil {
-			log.Printf("Failed to disconnect from MongoDB: %v", err)
+
+	var store database.GameStore
+	switch backend {
+	case database.BackendMemory:
+		store = database.NewMemoryStore()
+		fmt.Println("Using the in-memory store: game reports are lost when the server stops.")
+
+	case database.BackendSQLite:
+		openCtx, openCancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer openCancel()
+
+		sqliteStore, err := database.OpenSQLiteStore(openCtx, "") // Path from SQLITE_PATH
+		if err != nil {
+			log.Fatalf("Failed to open the SQLite database: %v", err)
+		}
+		defer sqliteStore.Close()
+		store = sqliteStore
+		fmt.Println("SQLite database opened. Setting up API server...")
+
+	default:
+		// Setup MongoDB connection
+		dbCtx, dbCancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer dbCancel()
+
+		// Using default URI from database pkg. Consider making this configurable via ENV for Docker.
+		mongoClient, err := database.ConnectDB(dbCtx, database.DefaultMongoDBURI)
+		if err != nil {
+			log.Fatalf("Failed to connect to MongoDB: %v", err)
+		}
+		defer func() {
+			disconnectCtx, cancelDisconnect := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancelDisconnect()
+			if err := mongoClient.Disconnect(disconnectCtx); err != nil {
+				log.Printf("Failed to disconnect from MongoDB: %v", err)
+			}
+			fmt.Println("Disconnected from MongoDB.")
+		}()
+		gameCollection := database.GetGameReportsCollection(mongoClient)
+		migrated, err := database.MigrateNumericGameIDs(dbCtx, gameCollection)
+		if err != nil {
+			log.Fatalf("Failed to migrate game IDs: %v", err)
+		}
+		if migrated > 0 {
+			fmt.Printf("Migrated %d game(s) with numeric IDs.\n", migrated)
 		}
-		fmt.Println("Disconnected from MongoDB.")
-	}()
-	// gameCollection := mongoClient.Database(databaseName).Collection(gameReportsCollection) // Old way
-	gameCollection := database.GetGameReportsCollection(mongoClient) // New way
+		if err := database.EnsureIndexes(dbCtx, gameCollection); err != nil {
+			log.Fatalf("Failed to create MongoDB indexes: %v", err)
+		}
+		store = database.NewMongoStore(gameCollection)
+
+		fmt.Println("MongoDB connected. Setting up API server...")
+	}
 
-	fmt.Println("MongoDB connected. Setting up API server...")
+	// Ratings are derived from the stored games; recomputing them keeps them in line with the current schema
+	refreshCtx, refreshCancel := context.WithTimeout(context.Background(), 60*time.Second)
+	refreshRatings(refreshCtx, store)
+	refreshCancel()
 
-	// --- API Setup --- 
+	// --- API Setup ---
 	fmt.Println("Starting API server on port 8080...")
-	router := SetupRouter(gameCollection) // SetupRouter is defined in routers.go
+	router := SetupRouter(store) // SetupRouter is defined in routers.go
 
 	// Start the server
 	if err := router.Run(":8080"); err != nil {
 		log.Fatalf("Failed to run server: %v", err)
 	}
-} 
\ No newline at end of file
+}
@@ -1,5 +1,10 @@
 package main
 
+import (
+	"quake_log_parser/database"
+	"quake_log_parser/reporter"
+)
+
 // ErrorResponse represents the structure of error responses returned by the API.
 // This is primarily used for Swagger documentation.
 type ErrorResponse struct {
@@ -15,6 +20,36 @@ type SuccessResponse struct {
 // UploadResponse represents the response for a file upload operation.
 // This is primarily used for Swagger documentation.
 type UploadResponse struct {
-	Message        string `json:"message"`
-	GamesProcessed int    `json:"games_processed"`
-} 
\ No newline at end of file
+	Message  string `json:"message"`
+	UploadID string `json:"upload_id"` // See GET /uploads/{id}
+	// Latest earlier upload of a file with the same SHA-256 and source, if any
+	DuplicateOfUpload string                 `json:"duplicate_of_upload,omitempty"`
+	GamesProcessed    int                    `json:"games_processed"`
+	GamesStored       int                    `json:"games_stored"`          // New games, stored by this upload
+	GamesDuplicate    int                    `json:"games_duplicate"`       // Games with the same lines as a stored game
+	GamesSkipped      int                    `json:"games_skipped"`         // Games of a file uploaded before, still stored
+	Source            string                 `json:"source"`                // Log source the game IDs were built from
+	GameIDs           []string               `json:"game_ids"`              // ID of every game in the file, in log order
+	Games             []database.GameOutcome `json:"games"`                 // What happened to every game in the file, in log order
+	Diagnostics       []reporter.Diagnostic  `json:"diagnostics,omitempty"` // Anomalies found while parsing the file
+}
+
+// StrictModeErrorResponse is returned when an upload in strict mode hits a parse anomaly.
+// This is primarily used for Swagger documentation.
+type StrictModeErrorResponse struct {
+	Error      string              `json:"error"`
+	Diagnostic reporter.Diagnostic `json:"diagnostic"` // The anomaly that aborted parsing
+}
+
+// UploadDetails is an upload with the games it stored first, returned by GET /uploads/{id}.
+type UploadDetails struct {
+	database.Upload
+	StoredGameIDs []string `json:"stored_game_ids"` // Deleting the upload deletes these games
+}
+
+// DeleteUploadResponse is returned when an upload is rolled back.
+// This is primarily used for Swagger documentation.
+type DeleteUploadResponse struct {
+	Message      string `json:"message"`
+	GamesDeleted int    `json:"games_deleted"`
+}
@@ -0,0 +1,76 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity tells how serious a parse anomaly is.
+type Severity string
+
+const (
+	SeverityWarning Severity = "warning" // The data was kept but may be incomplete
+	SeverityError   Severity = "error"   // The line could not be used at all
+)
+
+// DiagnosticCode identifies the kind of anomaly found while parsing.
+type DiagnosticCode string
+
+const (
+	DiagUnterminatedGame DiagnosticCode = "unterminated_game"  // A game never saw its ShutdownGame line
+	DiagOutsideGame      DiagnosticCode = "event_outside_game" // An event (e.g. a Kill) appeared before any InitGame or after ShutdownGame
+	DiagUnknownClient    DiagnosticCode = "unknown_client"     // An event referenced a client ID with no ClientUserinfoChanged
+	DiagUnparseableLine  DiagnosticCode = "unparseable_line"   // A line could not be read
+	DiagUnknownMeans     DiagnosticCode = "unknown_means"      // A Kill line named a means of death outside meansOfDeath_t
+	DiagMeansMismatch    DiagnosticCode = "means_id_mismatch"  // A Kill line's numeric means ID does not match its MOD_* name
+	DiagClockJump        DiagnosticCode = "clock_jump"         // A line's m:ss clock went backwards or jumped forward by more than MaxClockStep
+)
+
+// Diagnostic describes a single anomaly found while parsing a log.
+type Diagnostic struct {
+	Line     int // 1-based line number, 0 when the anomaly is not tied to a line (e.g. end of log)
+	Severity Severity
+	Code     DiagnosticCode
+	Message  string
+	Text     string // The offending line, if any
+	GameID   int    // Game the anomaly belongs to, 0 when it happened outside any game
+}
+
+func (d Diagnostic) String() string {
+	if d.Line == 0 {
+		return fmt.Sprintf("%s (%s): %s", d.Severity, d.Code, d.Message)
+	}
+	return fmt.Sprintf("line %d: %s (%s): %s", d.Line, d.Severity, d.Code, d.Message)
+}
+
+// Mode selects how Parse reacts to anomalies.
+type Mode string
+
+const (
+	ModeLenient Mode = "lenient" // Record every anomaly as a Diagnostic and keep going (the default)
+	ModeStrict  Mode = "strict"  // Abort on the first anomaly with a *ParseError
+)
+
+// ParseMode converts a user supplied mode name into a Mode. An empty string means ModeLenient.
+func ParseMode(s string) (Mode, error) {
+	switch Mode(strings.ToLower(strings.TrimSpace(s))) {
+	case "", ModeLenient:
+		return ModeLenient, nil
+	case ModeStrict:
+		return ModeStrict, nil
+	}
+	return "", fmt.Errorf("unknown parse mode %q (expected %q or %q)", s, ModeLenient, ModeStrict)
+}
+
+// ParseError is returned by Parse in strict mode for the first anomaly found.
+// Use errors.As to get at the line number and text.
+type ParseError struct {
+	Diagnostic
+}
+
+func (e *ParseError) Error() string {
+	if e.Text == "" {
+		return "strict parse failed: " + e.Diagnostic.String()
+	}
+	return fmt.Sprintf("strict parse failed: %s: %q", e.Diagnostic.String(), e.Text)
+}
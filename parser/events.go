@@ -0,0 +1,292 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EventType identifies the kind of log line an Event was read from.
+type EventType string
+
+const (
+	EventInitGame              EventType = "InitGame"
+	EventClientConnect         EventType = "ClientConnect"
+	EventClientUserinfoChanged EventType = "ClientUserinfoChanged"
+	EventClientBegin           EventType = "ClientBegin"
+	EventClientDisconnect      EventType = "ClientDisconnect"
+	EventItem                  EventType = "Item"
+	EventKill                  EventType = "Kill"
+	EventExit                  EventType = "Exit"
+	EventScore                 EventType = "score"
+	EventTeamScore             EventType = "teamscore"
+	EventSay                   EventType = "say"
+	EventShutdownGame          EventType = "ShutdownGame"
+)
+
+// WorldClientID is the client ID the server uses for <world> in Kill lines.
+const WorldClientID = 1022
+
+// Event is a single typed log line emitted by Parse through Options.OnEvent.
+// Use a type switch on the concrete *XxxEvent types to access the payload.
+type Event interface {
+	Meta() EventMeta
+}
+
+// EventMeta holds the fields shared by every event.
+type EventMeta struct {
+	Type EventType
+	Time time.Duration // The m:ss timestamp at the start of the line
+	Line int           // 1-based line number in the log
+}
+
+// Meta returns the shared event fields. It makes every struct embedding EventMeta an Event.
+func (m EventMeta) Meta() EventMeta { return m }
+
+// InitGameEvent starts a new game. Settings holds the server cvars from the line.
+type InitGameEvent struct {
+	EventMeta
+	Settings map[string]string
+}
+
+// ClientConnectEvent is emitted when a client takes a slot on the server.
+type ClientConnectEvent struct {
+	EventMeta
+	ClientID int
+}
+
+// ClientUserinfoChangedEvent carries a client's name and userinfo (team, model, ...).
+type ClientUserinfoChangedEvent struct {
+	EventMeta
+	ClientID int
+	Name     string
+	Info     map[string]string
+}
+
+// ClientBeginEvent is emitted when a connected client enters the game.
+type ClientBeginEvent struct {
+	EventMeta
+	ClientID int
+}
+
+// ClientDisconnectEvent is emitted when a client leaves its slot.
+type ClientDisconnectEvent struct {
+	EventMeta
+	ClientID int
+}
+
+// ItemEvent is emitted when a client picks up an item.
+type ItemEvent struct {
+	EventMeta
+	ClientID int
+	Item     string
+}
+
+// KillEvent is a single frag. KillerID is WorldClientID when <world> did the killing.
+type KillEvent struct {
+	EventMeta
+	KillerID int
+	VictimID int
+	MeansID  int
+	Killer   string
+	Victim   string
+	Means    string // MOD_* name as written in the log
+
+	MeansOfDeath MeansOfDeath // Means parsed into the enum, ModUnknown when the name is not in it
+}
+
+// ExitEvent marks the end of play, e.g. "Fraglimit hit.".
+type ExitEvent struct {
+	EventMeta
+	Reason string
+}
+
+// ScoreEvent is one row of the scoreboard the server prints after an Exit.
+type ScoreEvent struct {
+	EventMeta
+	Score    int
+	Ping     int
+	ClientID int
+	Name     string
+}
+
+// TeamScoreEvent holds the final "red:N  blue:M" line of team games.
+type TeamScoreEvent struct {
+	EventMeta
+	Red  int
+	Blue int
+}
+
+// SayEvent is a chat message. The log does not carry the client ID,
+// so Text still starts with the speaker's name ("Isgalamido: gg").
+type SayEvent struct {
+	EventMeta
+	Text string
+}
+
+// ShutdownGameEvent ends the current game.
+type ShutdownGameEvent struct {
+	EventMeta
+}
+
+// errMalformedLine is returned by parseLine for lines of a known type whose payload cannot be read.
+var errMalformedLine = errors.New("malformed log line")
+
+// errUnrecognizedLine is returned by parseLine for lines that are neither "m:ss Type: ..." events nor separators.
+var errUnrecognizedLine = errors.New("unrecognized log line")
+
+var (
+	reLine      = regexp.MustCompile(`^\s*(\d+):(\d{2})\s+([A-Za-z]+):\s*(.*)$`)
+	reSeparator = regexp.MustCompile(`^\s*\d+:\d{2}(\s+-*)?$`)
+	reClientID  = regexp.MustCompile(`^(\d+)$`)
+	reUserinfo  = regexp.MustCompile(`^(\d+)\s+(.*)$`)
+	reItem      = regexp.MustCompile(`^(\d+)\s+(\S+)$`)
+	reKill      = regexp.MustCompile(`^(\d+) (\d+) (\d+): (.*) killed (.*) by (MOD_[A-Z_]+)$`)
+	reScore     = regexp.MustCompile(`^(-?\d+)\s+ping:\s*(\d+)\s+client:\s*(\d+)\s+(.*)$`)
+	reTeamScore = regexp.MustCompile(`^(-?\d+)\s+blue:\s*(-?\d+)$`)
+)
+
+// parseLine turns one raw log line into a typed event.
+// It returns (nil, nil) for lines that carry no event (separators, unknown line types),
+// errUnrecognizedLine for lines that do not look like log lines at all
+// and errMalformedLine for known line types whose payload does not match the expected format.
+func parseLine(lineNumber int, line string) (Event, error) {
+	m := reLine.FindStringSubmatch(line)
+	if m == nil {
+		if reSeparator.MatchString(line) {
+			return nil, nil
+		}
+		return nil, errUnrecognizedLine
+	}
+	minutes, _ := strconv.Atoi(m[1])
+	seconds, _ := strconv.Atoi(m[2])
+	meta := EventMeta{
+		Time: time.Duration(minutes)*time.Minute + time.Duration(seconds)*time.Second,
+		Line: lineNumber,
+	}
+	payload := strings.TrimSpace(m[4])
+
+	switch m[3] {
+	case "InitGame":
+		meta.Type = EventInitGame
+		return &InitGameEvent{EventMeta: meta, Settings: parseInfoString(payload)}, nil
+
+	case "ClientConnect", "ClientBegin", "ClientDisconnect":
+		idMatch := reClientID.FindStringSubmatch(payload)
+		if idMatch == nil {
+			return nil, errMalformedLine
+		}
+		clientID, _ := strconv.Atoi(idMatch[1])
+		switch m[3] {
+		case "ClientConnect":
+			meta.Type = EventClientConnect
+			return &ClientConnectEvent{EventMeta: meta, ClientID: clientID}, nil
+		case "ClientBegin":
+			meta.Type = EventClientBegin
+			return &ClientBeginEvent{EventMeta: meta, ClientID: clientID}, nil
+		default:
+			meta.Type = EventClientDisconnect
+			return &ClientDisconnectEvent{EventMeta: meta, ClientID: clientID}, nil
+		}
+
+	case "ClientUserinfoChanged":
+		uMatch := reUserinfo.FindStringSubmatch(payload)
+		if uMatch == nil {
+			return nil, errMalformedLine
+		}
+		info := parseInfoString(uMatch[2])
+		name := strings.TrimSpace(info["n"])
+		if name == "" {
+			return nil, errMalformedLine
+		}
+		clientID, _ := strconv.Atoi(uMatch[1])
+		meta.Type = EventClientUserinfoChanged
+		return &ClientUserinfoChangedEvent{EventMeta: meta, ClientID: clientID, Name: name, Info: info}, nil
+
+	case "Item":
+		iMatch := reItem.FindStringSubmatch(payload)
+		if iMatch == nil {
+			return nil, errMalformedLine
+		}
+		clientID, _ := strconv.Atoi(iMatch[1])
+		meta.Type = EventItem
+		return &ItemEvent{EventMeta: meta, ClientID: clientID, Item: iMatch[2]}, nil
+
+	case "Kill":
+		kMatch := reKill.FindStringSubmatch(payload)
+		if kMatch == nil {
+			return nil, errMalformedLine
+		}
+		killerID, _ := strconv.Atoi(kMatch[1])
+		victimID, _ := strconv.Atoi(kMatch[2])
+		meansID, _ := strconv.Atoi(kMatch[3])
+		means, _ := ParseMeansOfDeath(kMatch[6])
+		meta.Type = EventKill
+		return &KillEvent{
+			EventMeta: meta,
+			KillerID:  killerID,
+			VictimID:  victimID,
+			MeansID:   meansID,
+			Killer:    strings.TrimSpace(kMatch[4]),
+			Victim:    strings.TrimSpace(kMatch[5]),
+			Means:     kMatch[6],
+
+			MeansOfDeath: means,
+		}, nil
+
+	case "Exit":
+		meta.Type = EventExit
+		return &ExitEvent{EventMeta: meta, Reason: payload}, nil
+
+	case "score":
+		sMatch := reScore.FindStringSubmatch(payload)
+		if sMatch == nil {
+			return nil, errMalformedLine
+		}
+		score, _ := strconv.Atoi(sMatch[1])
+		ping, _ := strconv.Atoi(sMatch[2])
+		clientID, _ := strconv.Atoi(sMatch[3])
+		meta.Type = EventScore
+		return &ScoreEvent{EventMeta: meta, Score: score, Ping: ping, ClientID: clientID, Name: strings.TrimSpace(sMatch[4])}, nil
+
+	case "red":
+		tMatch := reTeamScore.FindStringSubmatch(payload)
+		if tMatch == nil {
+			return nil, errMalformedLine
+		}
+		red, _ := strconv.Atoi(tMatch[1])
+		blue, _ := strconv.Atoi(tMatch[2])
+		meta.Type = EventTeamScore
+		return &TeamScoreEvent{EventMeta: meta, Red: red, Blue: blue}, nil
+
+	case "say":
+		meta.Type = EventSay
+		return &SayEvent{EventMeta: meta, Text: payload}, nil
+
+	case "ShutdownGame":
+		meta.Type = EventShutdownGame
+		return &ShutdownGameEvent{EventMeta: meta}, nil
+	}
+
+	return nil, nil
+}
+
+// FormatTimestamp formats d the way the log does, as minutes:seconds ("1:07", "981:21").
+func FormatTimestamp(d time.Duration) string {
+	seconds := int(d / time.Second)
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
+
+// parseInfoString splits a Quake info string ("\key\value\key\value") into a map.
+// The leading backslash is optional, as ClientUserinfoChanged omits it.
+func parseInfoString(s string) map[string]string {
+	info := make(map[string]string)
+	parts := strings.Split(strings.TrimPrefix(s, `\`), `\`)
+	for i := 0; i+1 < len(parts); i += 2 {
+		info[parts[i]] = parts[i+1]
+	}
+	return info
+}
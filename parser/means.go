@@ -0,0 +1,174 @@
+package parser
+
+// MeansOfDeath is the meansOfDeath_t enum of the Quake III game code (bg_public.h).
+// Values follow the MISSIONPACK build, which inserts five means before MOD_GRAPPLE;
+// use MeansOfDeathByID to read the numeric ID of a Kill line from either build.
+type MeansOfDeath int
+
+const (
+	ModUnknown MeansOfDeath = iota
+	ModShotgun
+	ModGauntlet
+	ModMachinegun
+	ModGrenade
+	ModGrenadeSplash
+	ModRocket
+	ModRocketSplash
+	ModPlasma
+	ModPlasmaSplash
+	ModRailgun
+	ModLightning
+	ModBFG
+	ModBFGSplash
+	ModWater
+	ModSlime
+	ModLava
+	ModCrush
+	ModTelefrag
+	ModFalling
+	ModSuicide
+	ModTargetLaser
+	ModTriggerHurt
+	ModNail          // MISSIONPACK
+	ModChaingun      // MISSIONPACK
+	ModProximityMine // MISSIONPACK
+	ModKamikaze      // MISSIONPACK
+	ModJuiced        // MISSIONPACK
+	ModGrapple
+)
+
+// baseGrappleID is MOD_GRAPPLE's ID in builds without MISSIONPACK, where it directly follows MOD_TRIGGER_HURT.
+const baseGrappleID = int(ModTriggerHurt) + 1
+
+// Categories of means of death, see MeansOfDeath.Category.
+const (
+	MeansCategoryDirect        = "direct"        // A weapon hit
+	MeansCategorySplash        = "splash"        // Explosion damage
+	MeansCategoryMelee         = "melee"         // Gauntlet and grapple
+	MeansCategoryEnvironmental = "environmental" // The map did the killing: falls, lava, crushers, ...
+	MeansCategoryOther         = "other"         // Telefrags, /kill and unknown causes
+)
+
+var meansOfDeathInfo = [...]struct {
+	name     string
+	label    string
+	category string
+}{
+	ModUnknown:       {"MOD_UNKNOWN", "Unknown", MeansCategoryOther},
+	ModShotgun:       {"MOD_SHOTGUN", "Shotgun", MeansCategoryDirect},
+	ModGauntlet:      {"MOD_GAUNTLET", "Gauntlet", MeansCategoryMelee},
+	ModMachinegun:    {"MOD_MACHINEGUN", "Machinegun", MeansCategoryDirect},
+	ModGrenade:       {"MOD_GRENADE", "Grenade Launcher", MeansCategoryDirect},
+	ModGrenadeSplash: {"MOD_GRENADE_SPLASH", "Grenade Launcher (splash)", MeansCategorySplash},
+	ModRocket:        {"MOD_ROCKET", "Rocket Launcher", MeansCategoryDirect},
+	ModRocketSplash:  {"MOD_ROCKET_SPLASH", "Rocket Launcher (splash)", MeansCategorySplash},
+	ModPlasma:        {"MOD_PLASMA", "Plasma Gun", MeansCategoryDirect},
+	ModPlasmaSplash:  {"MOD_PLASMA_SPLASH", "Plasma Gun (splash)", MeansCategorySplash},
+	ModRailgun:       {"MOD_RAILGUN", "Railgun", MeansCategoryDirect},
+	ModLightning:     {"MOD_LIGHTNING", "Lightning Gun", MeansCategoryDirect},
+	ModBFG:           {"MOD_BFG", "BFG10K", MeansCategoryDirect},
+	ModBFGSplash:     {"MOD_BFG_SPLASH", "BFG10K (splash)", MeansCategorySplash},
+	ModWater:         {"MOD_WATER", "Drowned", MeansCategoryEnvironmental},
+	ModSlime:         {"MOD_SLIME", "Slime", MeansCategoryEnvironmental},
+	ModLava:          {"MOD_LAVA", "Lava", MeansCategoryEnvironmental},
+	ModCrush:         {"MOD_CRUSH", "Crushed", MeansCategoryEnvironmental},
+	ModTelefrag:      {"MOD_TELEFRAG", "Telefrag", MeansCategoryOther},
+	ModFalling:       {"MOD_FALLING", "Falling", MeansCategoryEnvironmental},
+	ModSuicide:       {"MOD_SUICIDE", "Suicide", MeansCategoryOther},
+	ModTargetLaser:   {"MOD_TARGET_LASER", "Laser", MeansCategoryEnvironmental},
+	ModTriggerHurt:   {"MOD_TRIGGER_HURT", "Map Hazard", MeansCategoryEnvironmental},
+	ModNail:          {"MOD_NAIL", "Nailgun", MeansCategoryDirect},
+	ModChaingun:      {"MOD_CHAINGUN", "Chaingun", MeansCategoryDirect},
+	ModProximityMine: {"MOD_PROXIMITY_MINE", "Proximity Launcher", MeansCategorySplash},
+	ModKamikaze:      {"MOD_KAMIKAZE", "Kamikaze", MeansCategorySplash},
+	ModJuiced:        {"MOD_JUICED", "Juiced", MeansCategorySplash},
+	ModGrapple:       {"MOD_GRAPPLE", "Grappling Hook", MeansCategoryMelee},
+}
+
+// meansOfDeathByName maps the MOD_* names to their value.
+var meansOfDeathByName = func() map[string]MeansOfDeath {
+	byName := make(map[string]MeansOfDeath, len(meansOfDeathInfo))
+	for m, info := range meansOfDeathInfo {
+		byName[info.name] = MeansOfDeath(m)
+	}
+	return byName
+}()
+
+// ParseMeansOfDeath returns the means of death named name ("MOD_RAILGUN").
+// ok is false for names outside the enum.
+func ParseMeansOfDeath(name string) (m MeansOfDeath, ok bool) {
+	m, ok = meansOfDeathByName[name]
+	return m, ok
+}
+
+// MeansOfDeathByID returns the means of death with numeric ID id.
+// The same ID means different things with and without MISSIONPACK, so missionPack selects the numbering.
+func MeansOfDeathByID(id int, missionPack bool) (MeansOfDeath, bool) {
+	if !missionPack {
+		switch {
+		case id == baseGrappleID:
+			return ModGrapple, true
+		case id >= int(ModUnknown) && id < baseGrappleID:
+			return MeansOfDeath(id), true
+		}
+		return ModUnknown, false
+	}
+	if id < int(ModUnknown) || id > int(ModGrapple) {
+		return ModUnknown, false
+	}
+	return MeansOfDeath(id), true
+}
+
+// MatchesID reports whether id is m's numeric ID in either the base or the MISSIONPACK numbering.
+func (m MeansOfDeath) MatchesID(id int) bool {
+	for _, missionPack := range []bool{false, true} {
+		if byID, ok := MeansOfDeathByID(id, missionPack); ok && byID == m {
+			return true
+		}
+	}
+	return false
+}
+
+func (m MeansOfDeath) valid() bool {
+	return m >= ModUnknown && m <= ModGrapple
+}
+
+// String returns the MOD_* name used in the log.
+func (m MeansOfDeath) String() string {
+	if !m.valid() {
+		return meansOfDeathInfo[ModUnknown].name
+	}
+	return meansOfDeathInfo[m].name
+}
+
+// Label returns a human-friendly weapon or cause name, e.g. "Rocket Launcher (splash)".
+func (m MeansOfDeath) Label() string {
+	if !m.valid() {
+		return meansOfDeathInfo[ModUnknown].label
+	}
+	return meansOfDeathInfo[m].label
+}
+
+// Category returns one of the MeansCategory* constants.
+func (m MeansOfDeath) Category() string {
+	if !m.valid() {
+		return MeansCategoryOther
+	}
+	return meansOfDeathInfo[m].category
+}
+
+// MeansLabel returns the label of the means of death named name, or name itself when it is not in the enum.
+func MeansLabel(name string) string {
+	if m, ok := ParseMeansOfDeath(name); ok {
+		return m.Label()
+	}
+	return name
+}
+
+// MeansCategory returns the category of the means of death named name, MeansCategoryOther when it is not in the enum.
+func MeansCategory(name string) string {
+	if m, ok := ParseMeansOfDeath(name); ok {
+		return m.Category()
+	}
+	return MeansCategoryOther
+}
@@ -0,0 +1,65 @@
+package parser
+
+import "testing"
+
+func TestMeansOfDeathByID(t *testing.T) {
+	cases := []struct {
+		id          int
+		missionPack bool
+		expected    MeansOfDeath
+		ok          bool
+	}{
+		{id: 10, expected: ModRailgun, ok: true},
+		{id: 22, expected: ModTriggerHurt, ok: true},
+		{id: 23, expected: ModGrapple, ok: true},
+		{id: 23, missionPack: true, expected: ModNail, ok: true},
+		{id: 28, missionPack: true, expected: ModGrapple, ok: true},
+		{id: 24, ok: false},
+		{id: 29, missionPack: true, ok: false},
+		{id: -1, ok: false},
+	}
+	for _, tc := range cases {
+		m, ok := MeansOfDeathByID(tc.id, tc.missionPack)
+		if ok != tc.ok || (ok && m != tc.expected) {
+			t.Errorf("MeansOfDeathByID(%d, %v): expected %v, %v; got %v, %v", tc.id, tc.missionPack, tc.expected, tc.ok, m, ok)
+		}
+	}
+}
+
+func TestMeansOfDeath_MatchesID(t *testing.T) {
+	if !ModGrapple.MatchesID(23) || !ModGrapple.MatchesID(28) {
+		t.Errorf("Expected MOD_GRAPPLE to match both its base and MISSIONPACK IDs")
+	}
+	if !ModKamikaze.MatchesID(26) || ModKamikaze.MatchesID(23) {
+		t.Errorf("Expected MOD_KAMIKAZE to match only ID 26")
+	}
+	if ModFalling.MatchesID(22) {
+		t.Errorf("Expected MOD_FALLING not to match ID 22")
+	}
+}
+
+func TestMeansOfDeath_NamesLabelsCategories(t *testing.T) {
+	m, ok := ParseMeansOfDeath("MOD_ROCKET_SPLASH")
+	if !ok || m != ModRocketSplash || m.String() != "MOD_ROCKET_SPLASH" {
+		t.Fatalf("Expected MOD_ROCKET_SPLASH to parse, got %v, %v", m, ok)
+	}
+	if m.Label() != "Rocket Launcher (splash)" || m.Category() != MeansCategorySplash {
+		t.Errorf("Unexpected label %q or category %q", m.Label(), m.Category())
+	}
+
+	categories := map[string]string{
+		"MOD_RAILGUN":      MeansCategoryDirect,
+		"MOD_GAUNTLET":     MeansCategoryMelee,
+		"MOD_TRIGGER_HURT": MeansCategoryEnvironmental,
+		"MOD_TELEFRAG":     MeansCategoryOther,
+		"MOD_NOT_A_THING":  MeansCategoryOther,
+	}
+	for name, category := range categories {
+		if got := MeansCategory(name); got != category {
+			t.Errorf("MeansCategory(%q): expected %q, got %q", name, category, got)
+		}
+	}
+	if MeansLabel("MOD_NOT_A_THING") != "MOD_NOT_A_THING" {
+		t.Errorf("Expected unknown means to be labelled with their name")
+	}
+}
@@ -1,5 +1,12 @@
 package parser
 
+import (
+	"fmt"
+	"hash"
+	"strings"
+	"time"
+)
+
 // Game struct now uses int for ID
 type Game struct {
 	ID            int // Changed from string to int
@@ -7,11 +14,225 @@ type Game struct {
 	Players       map[string]*Player
 	KillsByPlayer map[string]int
 	KillsByMeans  map[string]int
-	ClientNames   map[string]string
+	ClientNames   map[string]string // Client ID -> last name seen on that slot
+	Hash          string            // Hex SHA-256 of the game's non-empty lines, InitGame through ShutdownGame, trimmed
+
+	// Server settings from the InitGame line
+	Settings  map[string]string // Every cvar on the InitGame line
+	Map       string            // mapname
+	GameType  int               // g_gametype, see the GameType* constants
+	FragLimit int               // fraglimit, 0 means no limit
+	TimeLimit int               // timelimit in minutes, 0 means no limit
+	Hostname  string            // sv_hostname
+
+	// Timing, from the m:ss timestamps of the game's lines
+	StartTime  time.Duration // Timestamp of the InitGame line
+	EndTime    time.Duration // Timestamp of the ShutdownGame line, or of the last line for unterminated games
+	Duration   time.Duration // Time from StartTime to EndTime, not counting clock jumps (see MaxClockStep)
+	ExitReason string        // Text of the Exit line, e.g. "Fraglimit hit."
+	Aborted    bool          // True when the game ended without an Exit line (map change, abort, truncated log)
+
+	Diagnostics []Diagnostic // Anomalies found while parsing this game
+
+	Scoreboard []ScoreEntry // Final scoreboard printed by the server after Exit, in log order
+
+	Kills      []Kill                    // Every Kill line, in log order
+	KillMatrix map[string]map[string]int // Killer -> victim -> times killed, by final player names. Suicides, <world> and kills between same-named slots are left out
+
+	Items map[string]int // Item -> times it was picked up, e.g. "weapon_railgun"
+
+	Chat []ChatMessage // say lines, in log order
+
+	// Team play, only filled for team gametypes (see IsTeamGame)
+	TeamScore   *TeamScore  // Final "red:N  blue:M" line, nil when the log has none
+	KillsByTeam map[int]int // Team -> enemies killed by its members
+	TeamKills   map[int]int // Team -> teammates killed by its members (friendly fire)
+
+	clients    map[int]*Player       // Current occupant of each client slot while parsing
+	connecting map[int]time.Duration // ClientConnect time of slots whose player has not announced a name yet
+	roster     []*Player             // Every player seen in the game, in order of appearance
+	digest     hash.Hash             // Running hash of the game's lines, see Hash
+	clock      time.Duration         // Time elapsed since StartTime, without clock jumps
 }
 
 // Player stores information about a player.
+// A player is identified by the client slot they occupy, so renames do not split their stats.
 type Player struct {
-	Name  string
-	Kills int // Net kills (actual kills - deaths by <world> or suicides) - This might be redundant if KillsByPlayer is the source of truth for scores.
-} 
\ No newline at end of file
+	Name     string   // Name the player ended the game with
+	ClientID int      // Client slot the player last occupied
+	Aliases  []string // Earlier names used during the game, oldest first
+	Score    int      // Net score used for KillsByPlayer: kills minus deaths by <world>, suicides and teamkills (which count -1, not +1)
+
+	Kills       int // Other players killed, teammates included
+	Deaths      int // Times the player died, by any cause
+	WorldDeaths int // Deaths caused by <world> (falls, lava, crushers, ...)
+	Suicides    int // Deaths caused by the player's own weapon
+
+	KillsByMeans  map[string]int // Means of death -> other players killed with it
+	DeathsByMeans map[string]int // Means of death -> times the player died by it, <world> and suicides included
+
+	victims map[*Player]int // Other players killed and how often, resolved to names by Game.finish
+
+	Items map[string]int // Item -> times this player picked it up
+
+	Team        int          // Team the player ended the game on, see the Team* constants
+	TeamHistory []TeamChange // Every team assignment, in log order
+	TeamKills   int          // Teammates this player killed (team gametypes only)
+
+	Sessions   []Session     // Connections to the server during the game, oldest first
+	Reconnects int           // Sessions after the first one
+	TimePlayed time.Duration // Sum of the sessions' TimePlayed
+}
+
+// Session is one connection of a player, from ClientConnect to ClientDisconnect or the end of the game.
+type Session struct {
+	ClientID       int
+	ConnectTime    time.Duration // Time of the ClientConnect line, or of the first line seen for the client if it had none
+	BeginTime      time.Duration // Time of the first ClientBegin line, valid when Began
+	Began          bool          // The client entered the game
+	DisconnectTime time.Duration // Time of the ClientDisconnect line, valid when Disconnected
+	Disconnected   bool          // False when the client was still connected when the game ended
+	TimePlayed     time.Duration // From ClientBegin to disconnect or game end, 0 if the client never began. Clock jumps are not counted
+
+	beginClock, disconnectClock time.Duration // Game clock at BeginTime and DisconnectTime, see Game.clock
+}
+
+// Kill is a single Kill line with both players resolved to the names they ended the game with.
+type Kill struct {
+	Time     time.Duration
+	KillerID int // WorldClientID for <world>
+	VictimID int
+	Killer   string // "<world>" for environmental deaths
+	Victim   string
+	Means    string // MOD_* name as written in the log
+	TeamKill bool   // Victim was on the killer's team in a team gametype
+
+	killer, victim *Player // killer is nil for <world>
+}
+
+// IsWorld reports whether the map killed the victim.
+func (k Kill) IsWorld() bool { return k.Killer == worldName }
+
+// IsSuicide reports whether the victim killed themselves.
+func (k Kill) IsSuicide() bool { return !k.IsWorld() && k.KillerID == k.VictimID }
+
+// ChatMessage is a say line attributed to the player who sent it.
+type ChatMessage struct {
+	Time     time.Duration
+	ClientID int    // -1 when the speaker could not be matched to a client slot
+	Name     string // Name the message was sent under
+	Player   string // Name the speaker ended the game with, empty when unresolved
+	Text     string // The message without the "Name: " prefix
+
+	player *Player
+}
+
+// TeamChange records a player joining a team at a point in the game.
+type TeamChange struct {
+	Time time.Duration // Timestamp of the ClientUserinfoChanged line
+	Team int
+}
+
+// TeamScore is the final team score printed by the server in team gametypes.
+type TeamScore struct {
+	Red  int
+	Blue int
+}
+
+// ScoreEntry is one row of the server's final scoreboard ("score: 20  ping: 4  client: 4 Zeh").
+type ScoreEntry struct {
+	Score    int
+	Ping     int
+	ClientID int
+	Name     string // Name printed by the server on the score line
+
+	ComputedScore int // Net Score of the player in ClientID when the line was printed, 0 if the slot was empty
+}
+
+// Game types as reported by the g_gametype cvar.
+const (
+	GameTypeFFA          = 0
+	GameTypeTournament   = 1
+	GameTypeSinglePlayer = 2
+	GameTypeTeam         = 3
+	GameTypeCTF          = 4
+)
+
+// GameTypeName returns a readable name for a g_gametype value.
+func GameTypeName(gameType int) string {
+	switch gameType {
+	case GameTypeFFA:
+		return "Free For All"
+	case GameTypeTournament:
+		return "Tournament"
+	case GameTypeSinglePlayer:
+		return "Single Player"
+	case GameTypeTeam:
+		return "Team Deathmatch"
+	case GameTypeCTF:
+		return "Capture The Flag"
+	}
+	return fmt.Sprintf("Unknown (%d)", gameType)
+}
+
+// IsTeamGame reports whether players are split into red and blue teams in gameType.
+func IsTeamGame(gameType int) bool {
+	return gameType >= GameTypeTeam
+}
+
+// Teams as reported by the t field of ClientUserinfoChanged.
+const (
+	TeamFree      = 0
+	TeamRed       = 1
+	TeamBlue      = 2
+	TeamSpectator = 3
+)
+
+// TeamName returns a readable name for a team value.
+func TeamName(team int) string {
+	switch team {
+	case TeamFree:
+		return "free"
+	case TeamRed:
+		return "red"
+	case TeamBlue:
+		return "blue"
+	case TeamSpectator:
+		return "spectator"
+	}
+	return fmt.Sprintf("unknown (%d)", team)
+}
+
+// Item classes returned by ItemClass.
+const (
+	ItemClassWeapon   = "weapon"
+	ItemClassAmmo     = "ammo"
+	ItemClassArmor    = "armor"
+	ItemClassHealth   = "health"
+	ItemClassPowerup  = "powerup"
+	ItemClassHoldable = "holdable"
+	ItemClassFlag     = "flag"
+	ItemClassOther    = "other"
+)
+
+// ItemClass groups an item name from an Item line ("weapon_railgun", "item_armor_shard", ...) into its class.
+func ItemClass(item string) string {
+	switch {
+	case strings.HasPrefix(item, "weapon_"):
+		return ItemClassWeapon
+	case strings.HasPrefix(item, "ammo_"):
+		return ItemClassAmmo
+	case strings.HasPrefix(item, "item_armor_"):
+		return ItemClassArmor
+	case item == "item_health" || strings.HasPrefix(item, "item_health_"):
+		return ItemClassHealth
+	case strings.HasPrefix(item, "holdable_"):
+		return ItemClassHoldable
+	case strings.HasPrefix(item, "team_CTF_"):
+		return ItemClassFlag
+	case strings.HasPrefix(item, "item_"):
+		// item_quad, item_haste, item_regen, item_invis, item_flight, item_enviro
+		return ItemClassPowerup
+	}
+	return ItemClassOther
+}
@@ -2,39 +2,88 @@ package parser
 
 import (
 	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
-	// "log" // Removed as it's not currently used
+	"io"
 	"os"
-	"regexp"
-	"strings" // Added for strings.Contains
-	// "regexp"
-	// "strings"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 )
 
-// Pre-compile regexes for efficiency
-var (
-	reClientUserinfoChanged = regexp.MustCompile(`^.*?ClientUserinfoChanged: (\d+) n\\([^\\]+)\\.*playerNameIsHere>([^<]+)<\\x{005c}*t\(\d+\).*$|^.*?ClientUserinfoChanged: (\d+) n\\(([^\\]+))\\\\t.*`)
-	reKill                  = regexp.MustCompile(`^.*?Kill: (\d+) (\d+) (\d+): (.*) killed (.*) by (MOD_[A-Z_]+)$`)
-)
+// DefaultMaxLineSize is the longest log line Parse accepts when Options.MaxLineSize is not set.
+// InitGame lines carry the whole server cvar string, so this is well above bufio's 64KB default.
+const DefaultMaxLineSize = 1024 * 1024
 
-// ParseLogFile reads and parses the Quake log file.
-// It returns a map of game data, keyed by game ID (int), and an error if any occurs.
-func ParseLogFile(filePath string) (map[int]*Game, error) { // Changed return type
-	// fmt.Println("Parsing log file:", filePath) // Can be noisy
+// Version identifies the parser build that read a log. Bump it whenever parsing changes what ends up in a game,
+// so stored uploads show which games were read by an older parser.
+const Version = "1.1"
+
+// MaxClockStep is the longest gap between two lines of a game that counts as time played.
+// The m:ss clock can wrap or jump within a game; longer steps, and any step backwards, add nothing to the game's clock.
+const MaxClockStep = time.Hour
+
+// Options controls how Parse reads a log stream.
+type Options struct {
+	// MaxLineSize is the maximum length of a single log line in bytes.
+	// Zero means DefaultMaxLineSize.
+	MaxLineSize int
+
+	// Mode selects lenient (default) or strict handling of anomalies.
+	// In strict mode Parse stops at the first anomaly and returns a *ParseError.
+	Mode Mode
 
+	// OnEvent, if set, receives every typed event in log order before it is applied to the games.
+	// Returning an error stops parsing and Parse returns that error.
+	OnEvent func(Event) error
+}
+
+// Result holds everything produced by a single Parse run.
+type Result struct {
+	Games       map[int]*Game // Parsed games keyed by their 1-based position in the log
+	Diagnostics []Diagnostic  // Every anomaly found, in log order. Those inside a game are also on Game.Diagnostics
+}
+
+// ParseLogFile reads and parses the Quake log file at filePath.
+// It is a thin wrapper around Parse for callers that work with files on disk.
+// It returns a map of game data, keyed by game ID (int), and an error if any occurs.
+func ParseLogFile(filePath string) (map[int]*Game, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("error opening file %s: %w", filePath, err)
 	}
 	defer file.Close()
 
-	games := make(map[int]*Game) // Changed map type
-	var currentGame *Game
-	gameCounter := 0 // This will be the int ID
+	result, err := Parse(context.Background(), file, Options{})
+	if err != nil {
+		return nil, fmt.Errorf("error parsing file %s: %w", filePath, err)
+	}
+	return result.Games, nil
+}
+
+// Parse reads a Quake log from r line by line and groups the data of each match.
+// It never buffers the whole stream, so r can be a multipart upload, stdin, a pipe or an in-memory buffer.
+// ctx is checked before every line; once it is done Parse stops and returns ctx.Err().
+func Parse(ctx context.Context, r io.Reader, opts Options) (*Result, error) {
+	maxLineSize := opts.MaxLineSize
+	if maxLineSize <= 0 {
+		maxLineSize = DefaultMaxLineSize
+	}
+
+	builder := newGameBuilder()
+	builder.strict = opts.Mode == ModeStrict
 
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(r)
+	// bufio.Scanner caps tokens at the larger of maxLineSize and the initial buffer's capacity.
+	scanner.Buffer(make([]byte, 0, min(4096, maxLineSize)), maxLineSize)
 	lineNumber := 0
 	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("parsing stopped at line %d: %w", lineNumber, err)
+		}
 		lineNumber++
 		line := strings.TrimSpace(scanner.Text())
 
@@ -42,106 +91,530 @@ func ParseLogFile(filePath string) (map[int]*Game, error) { // Changed return ty
 			continue
 		}
 
-		if strings.Contains(line, "InitGame:") {
-			// Finalize previous game if any (though ShutdownGame should handle this)
-			if currentGame != nil {
-				// This case should ideally not be hit if logs are well-formed with ShutdownGame
-				// log.Printf("Warning: InitGame encountered while a game (%d) was already in progress. Finalizing previous.", currentGame.ID) // Changed %s to %d
-				currentGame = nil
+		event, err := parseLine(lineNumber, line)
+		if _, starts := event.(*InitGameEvent); !starts {
+			builder.hashLine(line) // apply hashes an InitGame line itself, once the game it starts is open
+		}
+		if err != nil {
+			builder.diagnose(lineNumber, SeverityError, DiagUnparseableLine, line, "could not parse line: %v", err)
+			if builder.err != nil {
+				return nil, builder.err
+			}
+			continue
+		}
+		if event == nil {
+			// Lines without an event (separators, unknown line types) are skipped
+			continue
+		}
+
+		if opts.OnEvent != nil {
+			if err := opts.OnEvent(event); err != nil {
+				return nil, fmt.Errorf("event handler failed at line %d: %w", lineNumber, err)
+			}
+		}
+		builder.apply(event, line)
+		if builder.err != nil {
+			return nil, builder.err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading log (line %d): %w", lineNumber, err)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("parsing stopped at line %d: %w", lineNumber, err)
+	}
+
+	builder.finish()
+	if builder.err != nil {
+		return nil, builder.err
+	}
+
+	return &Result{Games: builder.games, Diagnostics: builder.diagnostics}, nil
+}
+
+// gameBuilder folds the event stream into Game values.
+type gameBuilder struct {
+	games       map[int]*Game
+	currentGame *Game
+	gameCounter int // Last assigned game ID
+	diagnostics []Diagnostic
+
+	strict bool        // Stop at the first anomaly
+	err    *ParseError // First anomaly in strict mode
+}
+
+func newGameBuilder() *gameBuilder {
+	return &gameBuilder{games: make(map[int]*Game)}
+}
+
+// apply updates the game state with a single event read from line.
+// Events other than InitGame are reported and ignored while no game is in progress.
+func (b *gameBuilder) apply(event Event, line string) {
+	meta := event.Meta()
+	if e, ok := event.(*InitGameEvent); ok {
+		// A new InitGame also closes a game that never saw its ShutdownGame
+		if b.currentGame != nil {
+			b.diagnose(meta.Line, SeverityWarning, DiagUnterminatedGame, line,
+				"game %d has no ShutdownGame before the next InitGame", b.currentGame.ID)
+		}
+		b.endGame()
+		b.gameCounter++
+		b.currentGame = newGame(b.gameCounter)
+		b.currentGame.applySettings(e.Settings)
+		b.currentGame.StartTime = e.Time
+		b.currentGame.EndTime = e.Time
+		b.hashLine(line)
+		b.games[b.currentGame.ID] = b.currentGame
+		return
+	}
+
+	game := b.currentGame
+	if game == nil {
+		b.diagnose(meta.Line, SeverityWarning, DiagOutsideGame, line, "%s line outside of any game", meta.Type)
+		return
+	}
+	b.advanceClock(game, meta, line)
+
+	switch e := event.(type) {
+	case *ShutdownGameEvent:
+		b.endGame()
+
+	case *ExitEvent:
+		game.ExitReason = e.Reason
+
+	case *TeamScoreEvent:
+		game.TeamScore = &TeamScore{Red: e.Red, Blue: e.Blue}
+
+	case *ScoreEvent:
+		entry := ScoreEntry{Score: e.Score, Ping: e.Ping, ClientID: e.ClientID, Name: e.Name}
+		if player, ok := game.clients[e.ClientID]; ok {
+			entry.ComputedScore = player.Score
+		}
+		game.Scoreboard = append(game.Scoreboard, entry)
+
+	case *ItemEvent:
+		game.Items[e.Item]++
+		player, ok := game.clients[e.ClientID]
+		if !ok {
+			b.diagnose(meta.Line, SeverityWarning, DiagUnknownClient, line,
+				"client %d has no ClientUserinfoChanged, the pickup only counts for the game", e.ClientID)
+			break
+		}
+		player.Items = incr(player.Items, e.Item)
+
+	case *SayEvent:
+		game.Chat = append(game.Chat, game.chatMessage(e))
+
+	case *ClientConnectEvent:
+		// The player is only known once the slot announces a name
+		game.connecting[e.ClientID] = meta.Time
+
+	case *ClientBeginEvent:
+		if player, ok := game.clients[e.ClientID]; ok {
+			// Team changes send ClientBegin again; only the first one starts play
+			if session := player.openSession(e.ClientID, meta.Time); !session.Began {
+				session.BeginTime = meta.Time
+				session.beginClock = game.clock
+				session.Began = true
 			}
-			gameCounter++
-			gameID := gameCounter // gameID is now int
-			currentGame = &Game{
-				ID:            gameID, // Changed
-				TotalKills:    0,
-				Players:       make(map[string]*Player),
-				KillsByPlayer: make(map[string]int),
-				KillsByMeans:  make(map[string]int),
-				ClientNames:   make(map[string]string),
+		}
+
+	case *ClientDisconnectEvent:
+		if player, ok := game.clients[e.ClientID]; ok {
+			session := player.openSession(e.ClientID, meta.Time)
+			session.DisconnectTime = meta.Time
+			session.disconnectClock = game.clock
+			session.Disconnected = true
+		}
+		// The slot is free again; whoever connects next is a new session
+		delete(game.clients, e.ClientID)
+		delete(game.connecting, e.ClientID)
+
+	case *ClientUserinfoChangedEvent:
+		game.ClientNames[strconv.Itoa(e.ClientID)] = e.Name
+		game.renameClient(e.ClientID, e.Name)
+		if connectTime, ok := game.connecting[e.ClientID]; ok {
+			player := game.clients[e.ClientID]
+			player.Sessions = append(player.Sessions, Session{ClientID: e.ClientID, ConnectTime: connectTime})
+			delete(game.connecting, e.ClientID)
+		}
+		if team, err := strconv.Atoi(e.Info["t"]); err == nil {
+			game.setTeam(game.clients[e.ClientID], team, meta.Time)
+		}
+
+	case *KillEvent:
+		game.TotalKills++
+		game.KillsByMeans[e.Means]++
+
+		if _, ok := ParseMeansOfDeath(e.Means); !ok {
+			b.diagnose(meta.Line, SeverityWarning, DiagUnknownMeans, line, "unknown means of death %s", e.Means)
+		} else if !e.MeansOfDeath.MatchesID(e.MeansID) {
+			b.diagnose(meta.Line, SeverityWarning, DiagMeansMismatch, line,
+				"means ID %d does not match %s (ID %d)", e.MeansID, e.Means, int(e.MeansOfDeath))
+		}
+		for _, clientID := range []int{e.KillerID, e.VictimID} {
+			if clientID != WorldClientID && !game.knownClient(clientID) {
+				b.diagnose(meta.Line, SeverityWarning, DiagUnknownClient, line,
+					"client %d has no ClientUserinfoChanged, using the name from the Kill line", clientID)
 			}
-			games[gameID] = currentGame // Changed
-			// fmt.Printf("Started game %d\n", gameID) // Optional: for debugging
-		} else if strings.Contains(line, "ShutdownGame:") {
-			if currentGame != nil {
-				// gameIDToPrint := currentGame.ID // Store ID before nil if needed for logging
-				currentGame = nil // End of current game processing
-				// fmt.Printf("Ended game %d\n", gameIDToPrint) // Optional: for debugging
+		}
+
+		// Kills are attributed to client slots, so a player who renames keeps their score
+		victim := game.clientPlayer(e.VictimID, e.Victim)
+		kill := Kill{Time: e.Time, KillerID: e.KillerID, VictimID: e.VictimID, Means: e.Means, victim: victim}
+		victim.Deaths++
+		victim.DeathsByMeans = incr(victim.DeathsByMeans, e.Means)
+		if e.KillerID == WorldClientID || e.Killer == worldName {
+			victim.WorldDeaths++
+			victim.Score--
+		} else if e.KillerID == e.VictimID { // Suicide
+			victim.Suicides++
+			victim.Score--
+			kill.killer = victim
+		} else { // Player killed another player
+			killer := game.clientPlayer(e.KillerID, e.Killer)
+			killer.Kills++
+			killer.KillsByMeans = incr(killer.KillsByMeans, e.Means)
+			if killer.victims == nil {
+				killer.victims = make(map[*Player]int)
 			}
-		} else if currentGame != nil { // Process lines only if we are inside a game
-			// Attempt to parse ClientUserinfoChanged
-			matches := reClientUserinfoChanged.FindStringSubmatch(line)
-			if len(matches) > 0 {
-				var clientID, playerName string
-				// The regex has two alternate patterns.
-				// Check which group of capturing parentheses has the match.
-				if matches[1] != "" && matches[3] != "" { // playerNameIsHere variant (use group 3 for name)
-					clientID = matches[1]
-					playerName = matches[3]
-				} else if matches[4] != "" && matches[6] != "" { // standard n\\NAME\\t variant (use group 6 for name)
-					clientID = matches[4]
-					playerName = matches[6]
-				}
-
-				if clientID != "" && playerName != "" {
-					playerName = strings.TrimSpace(playerName)
-					currentGame.ClientNames[clientID] = playerName
-					// Ensure player is in KillsByPlayer and Players map
-					if _, ok := currentGame.KillsByPlayer[playerName]; !ok {
-						currentGame.KillsByPlayer[playerName] = 0
-					}
-					if _, ok := currentGame.Players[playerName]; !ok {
-						currentGame.Players[playerName] = &Player{Name: playerName, Kills: 0} // Kills here might be redundant
-					}
-				} else {
-					// log.Printf("Line %d: Failed to extract ClientID/PlayerName from ClientUserinfoChanged: %s (Matches: %v)", lineNumber, line, matches)
-				}
+			killer.victims[victim]++
+			kill.TeamKill = game.countTeamKill(killer, victim)
+			if kill.TeamKill {
+				killer.Score-- // Quake 3 takes a point away for killing a teammate
 			} else {
-				// Attempt to parse Kill line if not ClientUserinfoChanged
-				killMatches := reKill.FindStringSubmatch(line)
-				if len(killMatches) == 7 {
-					// killerClientID := killMatches[1] // not directly used for scoring logic with current approach
-					// victimClientID := killMatches[2]   // not directly used
-					// meansOfDeathID := killMatches[3] // useful if mapping ID to string is needed later
-					killerName := strings.TrimSpace(killMatches[4])
-					victimName := strings.TrimSpace(killMatches[5])
-					mod := strings.TrimSpace(killMatches[6])
-
-					currentGame.TotalKills++
-					currentGame.KillsByMeans[mod]++
-
-					// Ensure victim is in score tracking, even if <world> killed them first
-					if _, ok := currentGame.KillsByPlayer[victimName]; !ok && victimName != "<world>" {
-						currentGame.KillsByPlayer[victimName] = 0
-						currentGame.Players[victimName] = &Player{Name: victimName, Kills: 0}
-					}
-
-					if killerName == "<world>" {
-						if victimName != "<world>" { // Should not happen but good check
-							currentGame.KillsByPlayer[victimName]--
-						}
-					} else {
-						// Ensure killer is in score tracking
-						if _, ok := currentGame.KillsByPlayer[killerName]; !ok {
-							currentGame.KillsByPlayer[killerName] = 0
-							currentGame.Players[killerName] = &Player{Name: killerName, Kills: 0}
-						}
-
-						if killerName == victimName { // Suicide
-							currentGame.KillsByPlayer[killerName]--
-						} else { // Player killed another player
-							currentGame.KillsByPlayer[killerName]++
-						}
-					}
-				} else if len(killMatches) > 0 && len(killMatches) != 7 {
-					// log.Printf("Line %d: Kill line regex matched but got %d groups, expected 7: %s", lineNumber, len(killMatches), line)
-				}
+				killer.Score++
 			}
+			kill.killer = killer
 		}
+		game.Kills = append(game.Kills, kill)
 	}
+}
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading file %s (line %d): %w", filePath, lineNumber, err)
+// advanceClock moves the game's EndTime to the time of the line and adds the step to its clock,
+// unless the step goes backwards or is longer than MaxClockStep.
+func (b *gameBuilder) advanceClock(game *Game, meta EventMeta, line string) {
+	step := meta.Time - game.EndTime
+	if step < 0 || step > MaxClockStep {
+		b.diagnose(meta.Line, SeverityWarning, DiagClockJump, line,
+			"clock jumped from %s to %s; the jump is not counted as game time", FormatTimestamp(game.EndTime), FormatTimestamp(meta.Time))
+	} else {
+		game.clock += step
+	}
+	game.EndTime = meta.Time
+}
+
+// hashLine adds line to the Hash of the game in progress, if any.
+func (b *gameBuilder) hashLine(line string) {
+	if b.currentGame != nil {
+		b.currentGame.digest.Write([]byte(line))
+		b.currentGame.digest.Write([]byte{'\n'})
+	}
+}
+
+// endGame closes the game in progress, if any.
+func (b *gameBuilder) endGame() {
+	if b.currentGame != nil {
+		b.currentGame.finish()
+		b.currentGame = nil
+	}
+}
+
+// finish closes the game still in progress when the log ends.
+func (b *gameBuilder) finish() {
+	if b.currentGame != nil {
+		b.diagnose(0, SeverityWarning, DiagUnterminatedGame, "",
+			"log ended before game %d's ShutdownGame", b.currentGame.ID)
 	}
+	b.endGame()
+}
 
-	return games, nil
-} 
\ No newline at end of file
+// diagnose records an anomaly, attaching it to the game in progress if there is one.
+func (b *gameBuilder) diagnose(lineNumber int, severity Severity, code DiagnosticCode, text string, format string, args ...any) {
+	d := Diagnostic{
+		Line:     lineNumber,
+		Severity: severity,
+		Code:     code,
+		Message:  fmt.Sprintf(format, args...),
+		Text:     text,
+	}
+	if b.currentGame != nil {
+		d.GameID = b.currentGame.ID
+		b.currentGame.Diagnostics = append(b.currentGame.Diagnostics, d)
+	}
+	b.diagnostics = append(b.diagnostics, d)
+	if b.strict && b.err == nil {
+		b.err = &ParseError{Diagnostic: d}
+	}
+}
+
+// worldName is the pseudo-player the server names as killer for environmental deaths.
+const worldName = "<world>"
+
+func newGame(id int) *Game {
+	return &Game{
+		ID:            id,
+		Players:       make(map[string]*Player),
+		KillsByPlayer: make(map[string]int),
+		KillsByMeans:  make(map[string]int),
+		ClientNames:   make(map[string]string),
+		KillsByTeam:   make(map[int]int),
+		TeamKills:     make(map[int]int),
+		KillMatrix:    make(map[string]map[string]int),
+		Items:         make(map[string]int),
+		clients:       make(map[int]*Player),
+		connecting:    make(map[int]time.Duration),
+		digest:        sha256.New(),
+	}
+}
+
+// applySettings copies the InitGame cvars onto the game and fills the first-class setting fields.
+// Missing or non-numeric values leave the corresponding field at its zero value.
+func (g *Game) applySettings(settings map[string]string) {
+	g.Settings = settings
+	g.Map = settings["mapname"]
+	g.Hostname = settings["sv_hostname"]
+	g.GameType, _ = strconv.Atoi(settings["g_gametype"])
+	g.FragLimit, _ = strconv.Atoi(settings["fraglimit"])
+	g.TimeLimit, _ = strconv.Atoi(settings["timelimit"])
+}
+
+// clientPlayer returns the player currently occupying clientID.
+// If the slot has no known occupant yet (no ClientUserinfoChanged seen), name is used to find or create one.
+func (g *Game) clientPlayer(clientID int, name string) *Player {
+	if player, ok := g.clients[clientID]; ok {
+		return player
+	}
+	player := g.playerByName(name)
+	if player == nil {
+		player = &Player{Name: name}
+		g.roster = append(g.roster, player)
+	}
+	player.ClientID = clientID
+	g.clients[clientID] = player
+	return player
+}
+
+// knownClient reports whether a ClientUserinfoChanged (or earlier event) bound clientID to a player.
+func (g *Game) knownClient(clientID int) bool {
+	_, ok := g.clients[clientID]
+	return ok
+}
+
+// renameClient records the name announced for clientID.
+// A name change on an occupied slot is a rename; the old name becomes an alias.
+func (g *Game) renameClient(clientID int, name string) {
+	player, ok := g.clients[clientID]
+	if !ok {
+		g.clientPlayer(clientID, name)
+		return
+	}
+	if player.Name == name {
+		return
+	}
+	player.Aliases = appendAlias(player.Aliases, player.Name)
+	player.Name = name
+	// Switching back to an earlier name does not make it an alias
+	for i, alias := range player.Aliases {
+		if alias == name {
+			player.Aliases = append(player.Aliases[:i], player.Aliases[i+1:]...)
+			break
+		}
+	}
+}
+
+// setTeam records that player is on team from time t on. Repeating the current team is not a change.
+func (g *Game) setTeam(player *Player, team int, t time.Duration) {
+	if len(player.TeamHistory) > 0 && player.Team == team {
+		return
+	}
+	player.Team = team
+	player.TeamHistory = append(player.TeamHistory, TeamChange{Time: t, Team: team})
+}
+
+// countTeamKill adds a player-on-player kill to the team totals and reports whether the victim was a teammate.
+// It does nothing outside team gametypes or when the killer is not on red or blue.
+func (g *Game) countTeamKill(killer, victim *Player) bool {
+	if !IsTeamGame(g.GameType) || (killer.Team != TeamRed && killer.Team != TeamBlue) {
+		return false
+	}
+	if killer.Team == victim.Team {
+		killer.TeamKills++
+		g.TeamKills[killer.Team]++
+		return true
+	}
+	g.KillsByTeam[killer.Team]++
+	return false
+}
+
+// chatMessage attributes a say line to the connected player whose name prefixes it.
+// Names may contain ": ", so the longest matching name wins.
+// When nobody matches, the text before the first ": " is taken as the name.
+func (g *Game) chatMessage(e *SayEvent) ChatMessage {
+	msg := ChatMessage{Time: e.Time, ClientID: -1, Text: e.Text}
+	for clientID, player := range g.clients {
+		if !strings.HasPrefix(e.Text, player.Name+": ") || len(player.Name) < len(msg.Name) {
+			continue
+		}
+		if len(player.Name) == len(msg.Name) && clientID > msg.ClientID {
+			continue // Same name on two slots: keep the lower slot so the result does not depend on map order
+		}
+		msg.ClientID = clientID
+		msg.Name = player.Name
+		msg.player = player
+	}
+	if msg.player != nil {
+		msg.Text = strings.TrimPrefix(e.Text, msg.Name+": ")
+	} else if name, text, ok := strings.Cut(e.Text, ": "); ok {
+		msg.Name, msg.Text = name, text
+	}
+	return msg
+}
+
+// openSession returns the player's session in progress, starting one at t if the player has none.
+// Clients that were already connected when the log starts have no ClientConnect line.
+func (p *Player) openSession(clientID int, t time.Duration) *Session {
+	if n := len(p.Sessions); n > 0 && !p.Sessions[n-1].Disconnected {
+		return &p.Sessions[n-1]
+	}
+	p.Sessions = append(p.Sessions, Session{ClientID: clientID, ConnectTime: t})
+	return &p.Sessions[len(p.Sessions)-1]
+}
+
+// playerByName returns a player of this game not bound to any slot whose current name is name.
+// This lets a player who disconnects and comes back under the same name keep a single entry.
+func (g *Game) playerByName(name string) *Player {
+	for _, player := range g.roster {
+		if player.Name != name {
+			continue
+		}
+		if occupant, ok := g.clients[player.ClientID]; ok && occupant == player {
+			continue
+		}
+		return player
+	}
+	return nil
+}
+
+// finish builds the name-keyed Players and KillsByPlayer maps from the slot roster
+// and settles the game's timing. Entries that ended the game under the same name are merged.
+func (g *Game) finish() {
+	g.Duration = g.clock
+	g.Hash = hex.EncodeToString(g.digest.Sum(nil))
+	g.Aborted = g.ExitReason == ""
+	for _, player := range g.roster {
+		for i := range player.Sessions {
+			session := &player.Sessions[i]
+			if !session.Began {
+				continue
+			}
+			end := g.clock
+			if session.Disconnected {
+				end = session.disconnectClock
+			}
+			session.TimePlayed = max(end-session.beginClock, 0)
+		}
+	}
+	for i := range g.Kills {
+		kill := &g.Kills[i]
+		kill.Victim = kill.victim.Name
+		kill.Killer = worldName
+		if kill.killer != nil {
+			kill.Killer = kill.killer.Name
+		}
+	}
+	for i := range g.Chat {
+		if g.Chat[i].player != nil {
+			g.Chat[i].Player = g.Chat[i].player.Name
+		}
+	}
+
+	g.Players = make(map[string]*Player, len(g.roster))
+	g.KillsByPlayer = make(map[string]int, len(g.roster))
+	for _, player := range g.roster {
+		if player.Name == worldName {
+			continue
+		}
+		existing, ok := g.Players[player.Name]
+		if !ok {
+			g.Players[player.Name] = player
+			g.KillsByPlayer[player.Name] = player.Score
+			continue
+		}
+		existing.Sessions = append(existing.Sessions, player.Sessions...)
+		sort.SliceStable(existing.Sessions, func(i, j int) bool {
+			return existing.Sessions[i].ConnectTime < existing.Sessions[j].ConnectTime
+		})
+		existing.Score += player.Score
+		existing.Kills += player.Kills
+		existing.Deaths += player.Deaths
+		existing.WorldDeaths += player.WorldDeaths
+		existing.Suicides += player.Suicides
+		existing.TeamKills += player.TeamKills
+		existing.Items = mergeCounts(existing.Items, player.Items)
+		existing.KillsByMeans = mergeCounts(existing.KillsByMeans, player.KillsByMeans)
+		existing.DeathsByMeans = mergeCounts(existing.DeathsByMeans, player.DeathsByMeans)
+		if len(player.TeamHistory) > 0 {
+			// The later entry holds the more recent team assignment
+			existing.Team = player.Team
+			existing.TeamHistory = append(existing.TeamHistory, player.TeamHistory...)
+		}
+		for _, alias := range player.Aliases {
+			if alias != existing.Name {
+				existing.Aliases = appendAlias(existing.Aliases, alias)
+			}
+		}
+		g.KillsByPlayer[player.Name] = existing.Score
+	}
+	g.KillMatrix = make(map[string]map[string]int)
+	for _, killer := range g.roster {
+		for victim, count := range killer.victims {
+			// Two slots that ended under the same name would show up as a suicide
+			if killer.Name == worldName || victim.Name == worldName || killer.Name == victim.Name {
+				continue
+			}
+			if g.KillMatrix[killer.Name] == nil {
+				g.KillMatrix[killer.Name] = make(map[string]int)
+			}
+			g.KillMatrix[killer.Name][victim.Name] += count
+		}
+	}
+	for _, player := range g.Players {
+		player.Reconnects = max(len(player.Sessions)-1, 0)
+		player.TimePlayed = 0
+		for _, session := range player.Sessions {
+			player.TimePlayed += session.TimePlayed
+		}
+	}
+}
+
+// incr adds one to counts[key], allocating counts if needed, and returns it.
+func incr(counts map[string]int, key string) map[string]int {
+	if counts == nil {
+		counts = make(map[string]int)
+	}
+	counts[key]++
+	return counts
+}
+
+// mergeCounts adds every count of src to dst, allocating dst if needed, and returns it.
+func mergeCounts(dst, src map[string]int) map[string]int {
+	for key, count := range src {
+		if dst == nil {
+			dst = make(map[string]int)
+		}
+		dst[key] += count
+	}
+	return dst
+}
+
+// appendAlias adds alias to aliases unless it is already present.
+func appendAlias(aliases []string, alias string) []string {
+	for _, a := range aliases {
+		if a == alias {
+			return aliases
+		}
+	}
+	return append(aliases, alias)
+}
@@ -0,0 +1,710 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const sampleLog = `  0:00 ------------------------------------------------------------
+  0:00 InitGame: \sv_hostname\Code Miner Server\g_gametype\0\fraglimit\20\timelimit\15\mapname\q3dm17
+  0:25 ClientConnect: 2
+  0:25 ClientUserinfoChanged: 2 n\Isgalamido\t\0\model\uriel/zael\hmodel\uriel/zael\g_redteam\\g_blueteam\\c1\5\c2\5\hc\100\w\0\l\0\tt\0\tl\0
+  0:27 ClientBegin: 2
+  0:29 ClientConnect: 3
+  0:29 ClientUserinfoChanged: 3 n\Dono da Bola\t\0\model\sarge\hmodel\sarge\g_redteam\\g_blueteam\\c1\4\c2\5\hc\95\w\0\l\0\tt\0\tl\0
+  0:30 Item: 2 weapon_rocketlauncher
+  1:10 Kill: 2 3 7: Isgalamido killed Dono da Bola by MOD_ROCKET_SPLASH
+  1:26 Kill: 1022 2 22: <world> killed Isgalamido by MOD_TRIGGER_HURT
+  1:40 Kill: 2 2 7: Isgalamido killed Isgalamido by MOD_ROCKET_SPLASH
+  1:41 Kill: 3 2 10: Dono da Bola killed Isgalamido by MOD_RAILGUN
+  1:55 Exit: Fraglimit hit.
+  1:55 score: 1  ping: 0  client: 3 Dono da Bola
+  1:55 score: -2  ping: 12  client: 2 Isgalamido
+  2:00 ShutdownGame:
+  2:00 ------------------------------------------------------------
+  2:00 InitGame: \sv_hostname\Code Miner Server\g_gametype\0\mapname\q3dm6
+  2:05 ClientConnect: 2
+  2:05 ClientUserinfoChanged: 2 n\Zeh\t\0\model\sarge\hmodel\sarge\g_redteam\\g_blueteam\\c1\5\c2\5\hc\100\w\0\l\0\tt\0\tl\0
+  2:30 ShutdownGame:
+`
+
+func TestParse_FromReader(t *testing.T) {
+	result, err := Parse(context.Background(), strings.NewReader(sampleLog), Options{})
+	if err != nil {
+		t.Fatalf("Parse returned an error: %v", err)
+	}
+
+	if len(result.Games) != 2 {
+		t.Fatalf("Expected 2 games, got %d", len(result.Games))
+	}
+
+	game := result.Games[1]
+	if game.TotalKills != 4 {
+		t.Errorf("Expected TotalKills 4, got %d", game.TotalKills)
+	}
+	expectedKills := map[string]int{"Isgalamido": -1, "Dono da Bola": 1}
+	for player, kills := range expectedKills {
+		if game.KillsByPlayer[player] != kills {
+			t.Errorf("Expected %s to have %d kills, got %d", player, kills, game.KillsByPlayer[player])
+		}
+	}
+	if game.KillsByMeans["MOD_ROCKET_SPLASH"] != 2 {
+		t.Errorf("Expected 2 MOD_ROCKET_SPLASH kills, got %d", game.KillsByMeans["MOD_ROCKET_SPLASH"])
+	}
+
+	if game.Map != "q3dm17" || game.Hostname != "Code Miner Server" || game.GameType != GameTypeFFA {
+		t.Errorf("Unexpected server settings: map %q, hostname %q, game type %d", game.Map, game.Hostname, game.GameType)
+	}
+	if game.FragLimit != 20 || game.TimeLimit != 15 {
+		t.Errorf("Expected fraglimit 20 and timelimit 15, got %d and %d", game.FragLimit, game.TimeLimit)
+	}
+
+	if game.StartTime != 0 || game.EndTime != 2*time.Minute || game.Duration != 2*time.Minute {
+		t.Errorf("Unexpected timing: start %v, end %v, duration %v", game.StartTime, game.EndTime, game.Duration)
+	}
+	if game.ExitReason != "Fraglimit hit." || game.Aborted {
+		t.Errorf("Expected game 1 to end by fraglimit, got reason %q (aborted %v)", game.ExitReason, game.Aborted)
+	}
+	if !result.Games[2].Aborted || result.Games[2].Duration != 30*time.Second {
+		t.Errorf("Expected game 2 to be aborted after 30s, got aborted %v, duration %v", result.Games[2].Aborted, result.Games[2].Duration)
+	}
+
+	if _, ok := result.Games[2].Players["Zeh"]; !ok {
+		t.Errorf("Expected Zeh to be a player in game 2")
+	}
+}
+
+func TestParse_OnEvent(t *testing.T) {
+	var events []Event
+	opts := Options{OnEvent: func(e Event) error {
+		events = append(events, e)
+		return nil
+	}}
+
+	if _, err := Parse(context.Background(), strings.NewReader(sampleLog), opts); err != nil {
+		t.Fatalf("Parse returned an error: %v", err)
+	}
+
+	counts := make(map[EventType]int)
+	for _, e := range events {
+		counts[e.Meta().Type]++
+	}
+	expectedCounts := map[EventType]int{
+		EventInitGame:              2,
+		EventClientConnect:         3,
+		EventClientUserinfoChanged: 3,
+		EventClientBegin:           1,
+		EventItem:                  1,
+		EventKill:                  4,
+		EventExit:                  1,
+		EventScore:                 2,
+		EventShutdownGame:          2,
+	}
+	for eventType, count := range expectedCounts {
+		if counts[eventType] != count {
+			t.Errorf("Expected %d %s events, got %d", count, eventType, counts[eventType])
+		}
+	}
+
+	var kill *KillEvent
+	for _, e := range events {
+		if k, ok := e.(*KillEvent); ok {
+			kill = k
+			break
+		}
+	}
+	if kill == nil {
+		t.Fatalf("Expected at least one KillEvent")
+	}
+	if kill.Line != 9 || kill.Time != 70*time.Second {
+		t.Errorf("Expected first kill at line 9, 1:10; got line %d, %v", kill.Line, kill.Time)
+	}
+	if kill.KillerID != 2 || kill.VictimID != 3 || kill.MeansID != 7 || kill.Means != "MOD_ROCKET_SPLASH" {
+		t.Errorf("Unexpected kill payload: %+v", kill)
+	}
+}
+
+func TestParse_OnEventError(t *testing.T) {
+	stop := errors.New("stop")
+	opts := Options{OnEvent: func(e Event) error { return stop }}
+
+	_, err := Parse(context.Background(), strings.NewReader(sampleLog), opts)
+	if !errors.Is(err, stop) {
+		t.Fatalf("Expected the handler error to be returned, got %v", err)
+	}
+}
+
+func TestParseLine_ScoreAndTeamScore(t *testing.T) {
+	event, err := parseLine(1, "11:57 score: 20  ping: 4  client: 4 Zeh")
+	if err != nil {
+		t.Fatalf("parseLine returned an error: %v", err)
+	}
+	score, ok := event.(*ScoreEvent)
+	if !ok || score.Score != 20 || score.Ping != 4 || score.ClientID != 4 || score.Name != "Zeh" {
+		t.Errorf("Unexpected score event: %#v", event)
+	}
+
+	event, err = parseLine(2, "10:12 red:8  blue:6")
+	if err != nil {
+		t.Fatalf("parseLine returned an error: %v", err)
+	}
+	teamScore, ok := event.(*TeamScoreEvent)
+	if !ok || teamScore.Red != 8 || teamScore.Blue != 6 {
+		t.Errorf("Unexpected team score event: %#v", event)
+	}
+
+	if _, err := parseLine(3, "1:00 Kill: 2 3: Isgalamido killed Zeh"); !errors.Is(err, errMalformedLine) {
+		t.Errorf("Expected errMalformedLine for a truncated kill, got %v", err)
+	}
+}
+
+func TestParse_Scoreboard(t *testing.T) {
+	result, err := Parse(context.Background(), strings.NewReader(sampleLog), Options{})
+	if err != nil {
+		t.Fatalf("Parse returned an error: %v", err)
+	}
+
+	expected := []ScoreEntry{
+		{Score: 1, Ping: 0, ClientID: 3, Name: "Dono da Bola", ComputedScore: 1},
+		{Score: -2, Ping: 12, ClientID: 2, Name: "Isgalamido", ComputedScore: -1},
+	}
+	scoreboard := result.Games[1].Scoreboard
+	if len(scoreboard) != len(expected) {
+		t.Fatalf("Expected %d scoreboard entries, got %d", len(expected), len(scoreboard))
+	}
+	for i, entry := range expected {
+		if scoreboard[i] != entry {
+			t.Errorf("Scoreboard entry %d: expected %+v, got %+v", i, entry, scoreboard[i])
+		}
+	}
+	if len(result.Games[2].Scoreboard) != 0 {
+		t.Errorf("Expected no scoreboard for a game without Exit, got %+v", result.Games[2].Scoreboard)
+	}
+}
+
+func TestParse_ScoreboardSameName(t *testing.T) {
+	log := `  0:00 InitGame: \mapname\q3dm17
+  0:01 ClientUserinfoChanged: 2 n\A\t\0\model\sarge
+  0:01 ClientUserinfoChanged: 3 n\B\t\0\model\sarge
+  0:05 Kill: 2 3 10: A killed B by MOD_RAILGUN
+  0:06 ClientUserinfoChanged: 2 n\B\t\0\model\sarge
+  0:10 Exit: Fraglimit hit.
+  0:10 score: 1  ping: 0  client: 2 B
+  0:10 score: 0  ping: 0  client: 3 B
+  0:11 ShutdownGame:
+`
+	result, err := Parse(context.Background(), strings.NewReader(log), Options{})
+	if err != nil {
+		t.Fatalf("Parse returned an error: %v", err)
+	}
+	scoreboard := result.Games[1].Scoreboard
+	// Both slots end as B, so only the slot tells the killer's row from the victim's
+	if len(scoreboard) != 2 || scoreboard[0].ComputedScore != 1 || scoreboard[1].ComputedScore != 0 {
+		t.Errorf("Expected computed scores 1 and 0 by slot, got %+v", scoreboard)
+	}
+}
+
+func TestParse_Teams(t *testing.T) {
+	log := `  0:00 InitGame: \g_gametype\4\mapname\q3ctf1
+  0:01 ClientUserinfoChanged: 2 n\Isgalamido\t\3\model\sarge
+  0:02 ClientUserinfoChanged: 2 n\Isgalamido\t\1\model\sarge
+  0:02 ClientUserinfoChanged: 3 n\Zeh\t\1\model\sarge
+  0:03 ClientUserinfoChanged: 4 n\Mal\t\2\model\sarge
+  0:03 ClientUserinfoChanged: 4 n\Mal\t\2\model\sarge
+  0:10 Kill: 2 4 10: Isgalamido killed Mal by MOD_RAILGUN
+  0:11 Kill: 2 3 10: Isgalamido killed Zeh by MOD_RAILGUN
+  0:12 Kill: 4 2 6: Mal killed Isgalamido by MOD_ROCKET
+  0:13 Kill: 1022 3 22: <world> killed Zeh by MOD_TRIGGER_HURT
+  0:20 Exit: Capturelimit hit.
+  0:20 red:8  blue:6
+  0:21 ShutdownGame:
+`
+	result, err := Parse(context.Background(), strings.NewReader(log), Options{})
+	if err != nil {
+		t.Fatalf("Parse returned an error: %v", err)
+	}
+	game := result.Games[1]
+
+	if game.TeamScore == nil || *game.TeamScore != (TeamScore{Red: 8, Blue: 6}) {
+		t.Errorf("Expected team score red 8 blue 6, got %+v", game.TeamScore)
+	}
+	if game.KillsByTeam[TeamRed] != 1 || game.KillsByTeam[TeamBlue] != 1 {
+		t.Errorf("Expected one enemy kill per team, got %v", game.KillsByTeam)
+	}
+	if game.TeamKills[TeamRed] != 1 || game.TeamKills[TeamBlue] != 0 {
+		t.Errorf("Expected one red teamkill, got %v", game.TeamKills)
+	}
+
+	isgalamido := game.Players["Isgalamido"]
+	if isgalamido.Team != TeamRed || isgalamido.TeamKills != 1 {
+		t.Errorf("Expected Isgalamido on red with 1 teamkill, got team %d and %d teamkills", isgalamido.Team, isgalamido.TeamKills)
+	}
+	// +1 for Mal, -1 for teammate Zeh, as on the server's scoreboard
+	if isgalamido.Score != 0 || isgalamido.Kills != 2 {
+		t.Errorf("Expected Isgalamido to score 0 from 2 kills, got %d from %d", isgalamido.Score, isgalamido.Kills)
+	}
+	teamKills := 0
+	for _, kill := range game.Kills {
+		if kill.TeamKill {
+			teamKills++
+			if kill.Killer != "Isgalamido" || kill.Victim != "Zeh" {
+				t.Errorf("Expected only Isgalamido killing Zeh flagged as a teamkill, got %+v", kill)
+			}
+		}
+	}
+	if teamKills != 1 {
+		t.Errorf("Expected 1 kill flagged as a teamkill, got %d", teamKills)
+	}
+	expectedHistory := []TeamChange{{Time: time.Second, Team: TeamSpectator}, {Time: 2 * time.Second, Team: TeamRed}}
+	if len(isgalamido.TeamHistory) != len(expectedHistory) {
+		t.Fatalf("Expected team history %v, got %v", expectedHistory, isgalamido.TeamHistory)
+	}
+	for i, change := range expectedHistory {
+		if isgalamido.TeamHistory[i] != change {
+			t.Errorf("Team change %d: expected %+v, got %+v", i, change, isgalamido.TeamHistory[i])
+		}
+	}
+	if len(game.Players["Mal"].TeamHistory) != 1 {
+		t.Errorf("Expected a repeated team to be recorded once, got %v", game.Players["Mal"].TeamHistory)
+	}
+}
+
+func TestParse_Items(t *testing.T) {
+	result, err := Parse(context.Background(), strings.NewReader(sampleLog), Options{})
+	if err != nil {
+		t.Fatalf("Parse returned an error: %v", err)
+	}
+	game := result.Games[1]
+	if game.Items["weapon_rocketlauncher"] != 1 {
+		t.Errorf("Expected 1 weapon_rocketlauncher pickup in the game, got %v", game.Items)
+	}
+	if game.Players["Isgalamido"].Items["weapon_rocketlauncher"] != 1 {
+		t.Errorf("Expected Isgalamido to have picked up the rocket launcher, got %v", game.Players["Isgalamido"].Items)
+	}
+	if len(game.Players["Dono da Bola"].Items) != 0 {
+		t.Errorf("Expected no pickups for Dono da Bola, got %v", game.Players["Dono da Bola"].Items)
+	}
+}
+
+func TestItemClass(t *testing.T) {
+	classes := map[string]string{
+		"weapon_railgun":    ItemClassWeapon,
+		"ammo_rockets":      ItemClassAmmo,
+		"item_armor_shard":  ItemClassArmor,
+		"item_health":       ItemClassHealth,
+		"item_health_mega":  ItemClassHealth,
+		"item_quad":         ItemClassPowerup,
+		"holdable_medkit":   ItemClassHoldable,
+		"team_CTF_blueflag": ItemClassFlag,
+		"something_unknown": ItemClassOther,
+	}
+	for item, class := range classes {
+		if got := ItemClass(item); got != class {
+			t.Errorf("ItemClass(%q): expected %q, got %q", item, class, got)
+		}
+	}
+}
+
+func TestParse_Chat(t *testing.T) {
+	log := `  0:00 InitGame: \g_gametype\0\mapname\q3dm17
+  0:01 ClientUserinfoChanged: 2 n\Mal\t\0
+  0:01 ClientUserinfoChanged: 3 n\Mal: the best\t\0
+  0:05 say: Mal: the best: hi all
+  0:06 say: Mal: gg
+  0:07 ClientUserinfoChanged: 2 n\Malandro\t\0
+  0:08 say: Ghost: boo
+  0:09 ShutdownGame:
+`
+	result, err := Parse(context.Background(), strings.NewReader(log), Options{})
+	if err != nil {
+		t.Fatalf("Parse returned an error: %v", err)
+	}
+
+	expected := []ChatMessage{
+		{Time: 5 * time.Second, ClientID: 3, Name: "Mal: the best", Player: "Mal: the best", Text: "hi all"},
+		{Time: 6 * time.Second, ClientID: 2, Name: "Mal", Player: "Malandro", Text: "gg"},
+		{Time: 8 * time.Second, ClientID: -1, Name: "Ghost", Text: "boo"},
+	}
+	chat := result.Games[1].Chat
+	if len(chat) != len(expected) {
+		t.Fatalf("Expected %d chat messages, got %d", len(expected), len(chat))
+	}
+	for i, msg := range expected {
+		got := chat[i]
+		got.player = nil
+		if got != msg {
+			t.Errorf("Chat message %d: expected %+v, got %+v", i, msg, got)
+		}
+	}
+}
+
+func TestParse_Sessions(t *testing.T) {
+	log := `  0:00 InitGame: \g_gametype\0\mapname\q3dm17
+  0:10 ClientConnect: 2
+  0:10 ClientUserinfoChanged: 2 n\Isgalamido\t\0
+  0:12 ClientBegin: 2
+  0:40 ClientDisconnect: 2
+  0:50 ClientConnect: 4
+  0:50 ClientUserinfoChanged: 4 n\Isgalamido\t\0
+  0:52 ClientBegin: 4
+  0:55 ClientBegin: 4
+  1:00 ClientUserinfoChanged: 3 n\Zeh\t\0
+  1:02 ClientBegin: 3
+  1:30 ClientConnect: 5
+  1:30 ClientUserinfoChanged: 5 n\Mal\t\0
+  1:40 ClientDisconnect: 5
+  2:00 ShutdownGame:
+`
+	result, err := Parse(context.Background(), strings.NewReader(log), Options{})
+	if err != nil {
+		t.Fatalf("Parse returned an error: %v", err)
+	}
+	game := result.Games[1]
+
+	isgalamido := game.Players["Isgalamido"]
+	expected := []Session{
+		{ClientID: 2, ConnectTime: 10 * time.Second, BeginTime: 12 * time.Second, Began: true, DisconnectTime: 40 * time.Second, Disconnected: true, TimePlayed: 28 * time.Second,
+			beginClock: 12 * time.Second, disconnectClock: 40 * time.Second},
+		{ClientID: 4, ConnectTime: 50 * time.Second, BeginTime: 52 * time.Second, Began: true, TimePlayed: 68 * time.Second,
+			beginClock: 52 * time.Second},
+	}
+	if len(isgalamido.Sessions) != len(expected) {
+		t.Fatalf("Expected %d sessions for Isgalamido, got %+v", len(expected), isgalamido.Sessions)
+	}
+	for i, session := range expected {
+		if isgalamido.Sessions[i] != session {
+			t.Errorf("Session %d: expected %+v, got %+v", i, session, isgalamido.Sessions[i])
+		}
+	}
+	if isgalamido.Reconnects != 1 || isgalamido.TimePlayed != 96*time.Second {
+		t.Errorf("Expected 1 reconnect and 1m36s played, got %d and %v", isgalamido.Reconnects, isgalamido.TimePlayed)
+	}
+
+	// Zeh has no ClientConnect line: the session starts at the first line seen for the slot
+	zeh := game.Players["Zeh"]
+	if len(zeh.Sessions) != 1 || zeh.Sessions[0].ConnectTime != 62*time.Second || zeh.TimePlayed != 58*time.Second {
+		t.Errorf("Unexpected sessions for Zeh: %+v", zeh.Sessions)
+	}
+
+	// Mal never entered the game
+	mal := game.Players["Mal"]
+	if len(mal.Sessions) != 1 || mal.Sessions[0].Began || mal.TimePlayed != 0 {
+		t.Errorf("Unexpected sessions for Mal: %+v", mal.Sessions)
+	}
+}
+
+func TestParse_KillBreakdown(t *testing.T) {
+	result, err := Parse(context.Background(), strings.NewReader(sampleLog), Options{})
+	if err != nil {
+		t.Fatalf("Parse returned an error: %v", err)
+	}
+	game := result.Games[1]
+
+	isgalamido := game.Players["Isgalamido"]
+	if isgalamido.Kills != 1 || isgalamido.Deaths != 3 || isgalamido.WorldDeaths != 1 || isgalamido.Suicides != 1 || isgalamido.Score != -1 {
+		t.Errorf("Unexpected breakdown for Isgalamido: %+v", isgalamido)
+	}
+	dono := game.Players["Dono da Bola"]
+	if dono.Kills != 1 || dono.Deaths != 1 || dono.WorldDeaths != 0 || dono.Suicides != 0 || dono.Score != 1 {
+		t.Errorf("Unexpected breakdown for Dono da Bola: %+v", dono)
+	}
+
+	if isgalamido.KillsByMeans["MOD_ROCKET_SPLASH"] != 1 || len(isgalamido.KillsByMeans) != 1 {
+		t.Errorf("Unexpected kills by means for Isgalamido: %v", isgalamido.KillsByMeans)
+	}
+	expectedDeaths := map[string]int{"MOD_TRIGGER_HURT": 1, "MOD_ROCKET_SPLASH": 1, "MOD_RAILGUN": 1}
+	if len(isgalamido.DeathsByMeans) != len(expectedDeaths) {
+		t.Errorf("Expected deaths by means %v, got %v", expectedDeaths, isgalamido.DeathsByMeans)
+	}
+	for means, count := range expectedDeaths {
+		if isgalamido.DeathsByMeans[means] != count {
+			t.Errorf("Expected Isgalamido to have died %d times by %s, got %d", count, means, isgalamido.DeathsByMeans[means])
+		}
+	}
+}
+
+func TestParse_KillList(t *testing.T) {
+	result, err := Parse(context.Background(), strings.NewReader(sampleLog), Options{})
+	if err != nil {
+		t.Fatalf("Parse returned an error: %v", err)
+	}
+	kills := result.Games[1].Kills
+	if len(kills) != 4 {
+		t.Fatalf("Expected 4 kills, got %d", len(kills))
+	}
+
+	first := kills[0]
+	if first.Time != 70*time.Second || first.Killer != "Isgalamido" || first.Victim != "Dono da Bola" || first.Means != "MOD_ROCKET_SPLASH" {
+		t.Errorf("Unexpected first kill: %+v", first)
+	}
+	if first.IsWorld() || first.IsSuicide() {
+		t.Errorf("Expected the first kill to be a regular frag")
+	}
+	if !kills[1].IsWorld() || kills[1].Killer != "<world>" {
+		t.Errorf("Expected the second kill to be by <world>, got %+v", kills[1])
+	}
+	if !kills[2].IsSuicide() || kills[2].Killer != "Isgalamido" {
+		t.Errorf("Expected the third kill to be a suicide, got %+v", kills[2])
+	}
+}
+
+func TestParse_KillMatrix(t *testing.T) {
+	result, err := Parse(context.Background(), strings.NewReader(sampleLog), Options{})
+	if err != nil {
+		t.Fatalf("Parse returned an error: %v", err)
+	}
+	matrix := result.Games[1].KillMatrix
+	expected := map[string]map[string]int{
+		"Isgalamido":   {"Dono da Bola": 1},
+		"Dono da Bola": {"Isgalamido": 1},
+	}
+	if len(matrix) != len(expected) {
+		t.Fatalf("Expected matrix %v, got %v", expected, matrix)
+	}
+	for killer, victims := range expected {
+		for victim, count := range victims {
+			if matrix[killer][victim] != count {
+				t.Errorf("Expected %s to have killed %s %d times, got %d", killer, victim, count, matrix[killer][victim])
+			}
+		}
+		if len(matrix[killer]) != len(victims) {
+			t.Errorf("Unexpected victims for %s: %v", killer, matrix[killer])
+		}
+	}
+}
+
+func TestParse_RenameKeepsKills(t *testing.T) {
+	log := `  0:00 InitGame: \mapname\q3dm17
+  0:25 ClientConnect: 2
+  0:25 ClientUserinfoChanged: 2 n\Dono da Bola\t\0\model\sarge
+  0:26 ClientConnect: 3
+  0:26 ClientUserinfoChanged: 3 n\Isgalamido\t\0\model\xian
+  0:30 Kill: 2 3 10: Dono da Bola killed Isgalamido by MOD_RAILGUN
+  0:40 ClientUserinfoChanged: 2 n\Mocinha\t\0\model\sarge
+  0:50 Kill: 2 3 10: Mocinha killed Isgalamido by MOD_RAILGUN
+  1:00 Kill: 3 2 7: Isgalamido killed Mocinha by MOD_ROCKET_SPLASH
+  1:10 ClientDisconnect: 2
+  1:11 ClientConnect: 2
+  1:11 ClientUserinfoChanged: 2 n\Zeh\t\0\model\sarge
+  1:20 Kill: 2 3 10: Zeh killed Isgalamido by MOD_RAILGUN
+  1:30 ShutdownGame:
+`
+	result, err := Parse(context.Background(), strings.NewReader(log), Options{})
+	if err != nil {
+		t.Fatalf("Parse returned an error: %v", err)
+	}
+	game := result.Games[1]
+
+	expectedKills := map[string]int{"Mocinha": 2, "Isgalamido": 1, "Zeh": 1}
+	if len(game.KillsByPlayer) != len(expectedKills) {
+		t.Errorf("Expected %d players in KillsByPlayer, got %v", len(expectedKills), game.KillsByPlayer)
+	}
+	for player, kills := range expectedKills {
+		if game.KillsByPlayer[player] != kills {
+			t.Errorf("Expected %s to have %d kills, got %d", player, kills, game.KillsByPlayer[player])
+		}
+	}
+
+	mocinha := game.Players["Mocinha"]
+	if mocinha == nil || len(mocinha.Aliases) != 1 || mocinha.Aliases[0] != "Dono da Bola" {
+		t.Errorf("Expected Mocinha to have alias Dono da Bola, got %+v", mocinha)
+	}
+	if _, ok := game.Players["Dono da Bola"]; ok {
+		t.Errorf("Expected Dono da Bola to be reported only as an alias")
+	}
+}
+
+func TestParse_Diagnostics(t *testing.T) {
+	log := `  0:05 Kill: 1022 2 22: <world> killed Isgalamido by MOD_TRIGGER_HURT
+  0:10 InitGame: \mapname\q3dm17
+  0:20 Kill: 2 3 7: Isgalamido killed Zeh by
+  0:30 Kill: 1022 4 19: <world> killed Mal by MOD_FALLING
+ 26  0:00 ------------------------------------------------------------
+  0:00 InitGame: \mapname\q3dm6
+  0:10 ClientConnect: 2
+`
+	result, err := Parse(context.Background(), strings.NewReader(log), Options{})
+	if err != nil {
+		t.Fatalf("Parse returned an error: %v", err)
+	}
+
+	expected := []struct {
+		line   int
+		code   DiagnosticCode
+		gameID int
+	}{
+		{1, DiagOutsideGame, 0},
+		{3, DiagUnparseableLine, 1},
+		{4, DiagUnknownClient, 1},
+		{5, DiagUnparseableLine, 1},
+		{6, DiagUnterminatedGame, 1},
+		{0, DiagUnterminatedGame, 2},
+	}
+	if len(result.Diagnostics) != len(expected) {
+		t.Fatalf("Expected %d diagnostics, got %d: %v", len(expected), len(result.Diagnostics), result.Diagnostics)
+	}
+	for i, e := range expected {
+		d := result.Diagnostics[i]
+		if d.Line != e.line || d.Code != e.code || d.GameID != e.gameID {
+			t.Errorf("Diagnostic %d: expected line %d %s in game %d, got %v (game %d)", i, e.line, e.code, e.gameID, d, d.GameID)
+		}
+	}
+	if len(result.Games[1].Diagnostics) != 4 || len(result.Games[2].Diagnostics) != 1 {
+		t.Errorf("Expected 4 and 1 diagnostics on games 1 and 2, got %d and %d",
+			len(result.Games[1].Diagnostics), len(result.Games[2].Diagnostics))
+	}
+}
+
+func TestParse_ClockJumps(t *testing.T) {
+	log := `  0:00 InitGame: \mapname\q3dm17
+  0:10 ClientConnect: 2
+  0:10 ClientUserinfoChanged: 2 n\Isgalamido\t\0
+  0:10 ClientBegin: 2
+  1:00 Kill: 1022 2 22: <world> killed Isgalamido by MOD_TRIGGER_HURT
+981:06 Item: 2 weapon_rocketlauncher
+981:36 Item: 2 item_armor_shard
+  0:20 Item: 2 item_health
+  0:50 ShutdownGame:
+`
+	result, err := Parse(context.Background(), strings.NewReader(log), Options{})
+	if err != nil {
+		t.Fatalf("Parse returned an error: %v", err)
+	}
+	game := result.Games[1]
+
+	// 1:00 of play, then 30s after the forward jump and 30s after the backward one
+	if game.Duration != 2*time.Minute {
+		t.Errorf("Expected the jumps to be left out of a 2m duration, got %v", game.Duration)
+	}
+	if played := game.Players["Isgalamido"].TimePlayed; played != 110*time.Second {
+		t.Errorf("Expected Isgalamido to have played 1m50s, got %v", played)
+	}
+	jumps := 0
+	for _, d := range game.Diagnostics {
+		if d.Code == DiagClockJump {
+			jumps++
+			if d.Severity != SeverityWarning {
+				t.Errorf("Expected a clock jump to be a warning, got %+v", d)
+			}
+		}
+	}
+	if jumps != 2 {
+		t.Errorf("Expected 2 clock jump diagnostics, got %+v", game.Diagnostics)
+	}
+}
+
+func TestParse_MeansDiagnostics(t *testing.T) {
+	log := `  0:00 InitGame: \g_gametype\0\mapname\q3dm17
+  0:01 ClientUserinfoChanged: 2 n\Zeh\t\0
+  0:10 Kill: 1022 2 19: <world> killed Zeh by MOD_FALLING
+  0:11 Kill: 1022 2 22: <world> killed Zeh by MOD_FALLING
+  0:12 Kill: 1022 2 30: <world> killed Zeh by MOD_DISINTEGRATED
+  0:20 ShutdownGame:
+`
+	result, err := Parse(context.Background(), strings.NewReader(log), Options{})
+	if err != nil {
+		t.Fatalf("Parse returned an error: %v", err)
+	}
+	expected := []struct {
+		line int
+		code DiagnosticCode
+	}{
+		{line: 4, code: DiagMeansMismatch},
+		{line: 5, code: DiagUnknownMeans},
+	}
+	if len(result.Diagnostics) != len(expected) {
+		t.Fatalf("Expected %d diagnostics, got %v", len(expected), result.Diagnostics)
+	}
+	for i, e := range expected {
+		if d := result.Diagnostics[i]; d.Line != e.line || d.Code != e.code {
+			t.Errorf("Diagnostic %d: expected %s at line %d, got %v", i, e.code, e.line, d)
+		}
+	}
+	if result.Games[1].KillsByMeans["MOD_DISINTEGRATED"] != 1 {
+		t.Errorf("Expected unknown means to still be counted, got %v", result.Games[1].KillsByMeans)
+	}
+}
+
+func TestParse_GameHash(t *testing.T) {
+	parse := func(log string) map[int]*Game {
+		t.Helper()
+		result, err := Parse(context.Background(), strings.NewReader(log), Options{})
+		if err != nil {
+			t.Fatalf("Parse returned an error: %v", err)
+		}
+		return result.Games
+	}
+	games := parse(sampleLog)
+	if len(games[1].Hash) != 64 || games[1].Hash == games[2].Hash {
+		t.Fatalf("Expected two distinct SHA-256 hashes, got %q and %q", games[1].Hash, games[2].Hash)
+	}
+
+	// The second game on its own, without the separators and indentation around it, hashes the same
+	second := sampleLog[strings.Index(sampleLog, "  2:00 InitGame"):]
+	second = strings.ReplaceAll(second, "\n  ", "\n")
+	if hash := parse(second)[1].Hash; hash != games[2].Hash {
+		t.Errorf("Expected the second game alone to hash to %q, got %q", games[2].Hash, hash)
+	}
+
+	// Any change to the game's lines changes its hash
+	changed := strings.Replace(sampleLog, "MOD_RAILGUN", "MOD_SHOTGUN", 1)
+	if hash := parse(changed)[1].Hash; hash == games[1].Hash {
+		t.Error("Expected a changed kill to change the hash")
+	}
+}
+
+func TestParse_StrictMode(t *testing.T) {
+	log := `  0:00 InitGame: \mapname\q3dm17
+  0:10 ClientConnect: 2
+  0:20 Kill: 2 3 7: Isgalamido killed Zeh by
+  0:30 ShutdownGame:
+`
+	_, err := Parse(context.Background(), strings.NewReader(log), Options{Mode: ModeStrict})
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("Expected a *ParseError in strict mode, got %v", err)
+	}
+	if parseErr.Line != 3 || parseErr.Code != DiagUnparseableLine || !strings.Contains(parseErr.Text, "killed Zeh by") {
+		t.Errorf("Unexpected strict mode error: %+v", parseErr.Diagnostic)
+	}
+
+	result, err := Parse(context.Background(), strings.NewReader(log), Options{Mode: ModeLenient})
+	if err != nil {
+		t.Fatalf("Expected lenient mode to succeed, got %v", err)
+	}
+	if len(result.Diagnostics) != 1 {
+		t.Errorf("Expected 1 diagnostic in lenient mode, got %d", len(result.Diagnostics))
+	}
+}
+
+func TestParse_ContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := Parse(ctx, strings.NewReader(sampleLog), Options{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestParse_LineTooLong(t *testing.T) {
+	longLine := "  0:00 InitGame: \\" + strings.Repeat("x", 256) + "\n"
+
+	_, err := Parse(context.Background(), strings.NewReader(longLine), Options{MaxLineSize: 128})
+	if err == nil {
+		t.Fatalf("Expected an error for a line longer than MaxLineSize")
+	}
+}
+
+func TestParseLogFile_SampleLog(t *testing.T) {
+	games, err := ParseLogFile("../data/games.log")
+	if err != nil {
+		t.Fatalf("ParseLogFile returned an error: %v", err)
+	}
+	if len(games) != 21 {
+		t.Errorf("Expected 21 games in the sample log, got %d", len(games))
+	}
+}
@@ -0,0 +1,129 @@
+// Package rating computes Elo skill ratings by replaying stored games in order.
+package rating
+
+import (
+	"math"
+	"sort"
+
+	"quake_log_parser/reporter"
+)
+
+// Config holds the Elo parameters.
+type Config struct {
+	Initial    float64 // Rating of a player's first game
+	PlacementK float64 // Most a player can gain or lose from their final placement in one game
+	KillK      float64 // Most a single kill can move the killer's and victim's ratings
+}
+
+// DefaultConfig is the configuration used by the API.
+var DefaultConfig = Config{Initial: 1500, PlacementK: 32, KillK: 4}
+
+// PlayerRating is a player's current rating and how it got there.
+type PlayerRating struct {
+	Name    string         `json:"name" bson:"_id"`
+	Rating  float64        `json:"rating" bson:"rating"`
+	Peak    float64        `json:"peak" bson:"peak"`
+	Games   int            `json:"games" bson:"games"`
+	History []HistoryEntry `json:"history,omitempty" bson:"history"` // One entry per rated game, oldest first
+}
+
+// HistoryEntry is a player's rating after a game.
+type HistoryEntry struct {
+	GameID string  `json:"game_id" bson:"game_id"`
+	Rating float64 `json:"rating" bson:"rating"`
+	Change float64 `json:"change" bson:"change"`
+}
+
+// Compute replays reports in the given order (normally the order they were stored in) and returns every rated player, best first.
+//
+// Each game moves ratings in two ways, both measured against the ratings the players had when the game began:
+//   - Placement: every pair of players is a match won by the one with the higher net score (a draw when equal),
+//     weighted so a whole game is worth at most PlacementK.
+//   - Kills: every kill of an opponent is a duel won by the killer, worth at most KillK.
+//     Teamkills are not duels: their -1 already counts against the killer's placement.
+//
+// Games with fewer than two players do not change anything.
+func Compute(reports []reporter.GameReport, cfg Config) []PlayerRating {
+	players := make(map[string]*PlayerRating)
+	for _, report := range reports {
+		if len(report.Kills) < 2 {
+			continue
+		}
+		current := func(name string) float64 {
+			if p, ok := players[name]; ok {
+				return p.Rating
+			}
+			return cfg.Initial
+		}
+
+		// Sum in name order: float addition is not associative, so map order would make replays drift
+		names := make([]string, 0, len(report.Kills))
+		for name := range report.Kills {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		deltas := make(map[string]float64, len(report.Kills))
+		weight := cfg.PlacementK / float64(len(report.Kills)-1)
+		for _, a := range names {
+			for _, b := range names {
+				if a == b {
+					continue
+				}
+				scoreA, scoreB := report.Kills[a], report.Kills[b]
+				actual := 0.5
+				if scoreA > scoreB {
+					actual = 1
+				} else if scoreA < scoreB {
+					actual = 0
+				}
+				deltas[a] += weight * (actual - expected(current(a), current(b)))
+			}
+		}
+		for _, kill := range report.KillLog {
+			_, killerPlays := report.Kills[kill.Killer]
+			_, victimPlays := report.Kills[kill.Victim]
+			if !killerPlays || !victimPlays || kill.Suicide || kill.TeamKill {
+				continue // <world>, suicides, teamkills and players missing from the score table
+			}
+			change := cfg.KillK * (1 - expected(current(kill.Killer), current(kill.Victim)))
+			deltas[kill.Killer] += change
+			deltas[kill.Victim] -= change
+		}
+
+		for name, delta := range deltas {
+			p, ok := players[name]
+			if !ok {
+				p = &PlayerRating{Name: name, Rating: cfg.Initial, Peak: cfg.Initial}
+				players[name] = p
+			}
+			p.Rating += delta
+			p.Peak = math.Max(p.Peak, p.Rating)
+			p.Games++
+			p.History = append(p.History, HistoryEntry{GameID: report.ID, Rating: round(p.Rating), Change: round(delta)})
+		}
+	}
+
+	ratings := make([]PlayerRating, 0, len(players))
+	for _, p := range players {
+		p.Rating, p.Peak = round(p.Rating), round(p.Peak)
+		ratings = append(ratings, *p)
+	}
+	sort.Slice(ratings, func(i, j int) bool {
+		if ratings[i].Rating != ratings[j].Rating {
+			return ratings[i].Rating > ratings[j].Rating
+		}
+		return ratings[i].Name < ratings[j].Name
+	})
+	return ratings
+}
+
+// expected is the Elo probability that a player rated a beats one rated b.
+func expected(a, b float64) float64 {
+	return 1 / (1 + math.Pow(10, (b-a)/400))
+}
+
+// round rounds to two decimals for display and storage.
+func round(x float64) float64 {
+	return math.Round(x*100) / 100
+}
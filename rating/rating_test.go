@@ -0,0 +1,109 @@
+package rating
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+
+	"quake_log_parser/reporter"
+)
+
+func TestCompute_Placement(t *testing.T) {
+	reports := []reporter.GameReport{
+		{ID: "1", Kills: map[string]int{"Zeh": 10, "Mal": 2}},
+	}
+	ratings := Compute(reports, Config{Initial: 1500, PlacementK: 32})
+
+	if len(ratings) != 2 || ratings[0].Name != "Zeh" || ratings[1].Name != "Mal" {
+		t.Fatalf("Expected Zeh ahead of Mal, got %+v", ratings)
+	}
+	// Equal ratings expect a draw, so the winner takes half of K
+	if ratings[0].Rating != 1516 || ratings[1].Rating != 1484 {
+		t.Errorf("Expected 1516 and 1484, got %v and %v", ratings[0].Rating, ratings[1].Rating)
+	}
+	if ratings[0].Peak != 1516 || ratings[1].Peak != 1500 {
+		t.Errorf("Unexpected peaks: %v and %v", ratings[0].Peak, ratings[1].Peak)
+	}
+	if h := ratings[0].History; len(h) != 1 || h[0] != (HistoryEntry{GameID: "1", Rating: 1516, Change: 16}) {
+		t.Errorf("Unexpected history for Zeh: %+v", h)
+	}
+}
+
+func TestCompute_KillsAndReplayOrder(t *testing.T) {
+	reports := []reporter.GameReport{
+		{
+			ID:    "1",
+			Kills: map[string]int{"Zeh": 1, "Mal": 1},
+			KillLog: []reporter.KillEntry{
+				{Killer: "Zeh", Victim: "Mal"},
+				{Killer: "Zeh", Victim: "Mal"},
+				{Killer: "<world>", Victim: "Zeh"},
+				{Killer: "Mal", Victim: "Mal", Suicide: true},
+			},
+		},
+		{ID: "2", Kills: map[string]int{"Zeh": 0}}, // A single player is not rated
+		{ID: "3", Kills: map[string]int{"Zeh": 0, "Mal": 5, "Isgalamido": 5}},
+	}
+	ratings := Compute(reports, Config{Initial: 1500, PlacementK: 32, KillK: 4})
+
+	byName := make(map[string]PlayerRating)
+	total := 0.0
+	for _, r := range ratings {
+		byName[r.Name] = r
+		total += r.Rating
+	}
+
+	// Game 1 is a placement draw; the two kills at equal ratings give Zeh 2 + 2
+	if h := byName["Zeh"].History; len(h) != 2 || h[0].GameID != "1" || h[0].Change != 4 || h[1].GameID != "3" {
+		t.Errorf("Unexpected history for Zeh: %+v", h)
+	}
+	if byName["Zeh"].Games != 2 || byName["Isgalamido"].Games != 1 {
+		t.Errorf("Expected 2 rated games for Zeh and 1 for Isgalamido, got %d and %d", byName["Zeh"].Games, byName["Isgalamido"].Games)
+	}
+	// Elo is zero-sum
+	if math.Abs(total-3*1500) > 0.05 {
+		t.Errorf("Expected ratings to sum to %v, got %v", 3*1500, total)
+	}
+	if ratings[len(ratings)-1].Name != "Zeh" {
+		t.Errorf("Expected Zeh last after finishing last in game 3, got %+v", ratings)
+	}
+}
+
+func TestCompute_TeamKills(t *testing.T) {
+	reports := []reporter.GameReport{
+		{
+			ID:    "1",
+			Kills: map[string]int{"Zeh": 0, "Mal": 0},
+			KillLog: []reporter.KillEntry{
+				{Killer: "Zeh", Victim: "Mal", TeamKill: true},
+				{Killer: "Zeh", Victim: "Mal", TeamKill: true},
+			},
+		},
+	}
+	ratings := Compute(reports, Config{Initial: 1500, PlacementK: 32, KillK: 4})
+
+	for _, r := range ratings {
+		if r.Rating != 1500 {
+			t.Errorf("Expected teamkills to leave %s at 1500, got %v", r.Name, r.Rating)
+		}
+	}
+}
+
+func TestCompute_Reproducible(t *testing.T) {
+	var reports []reporter.GameReport
+	for game := 0; game < 20; game++ {
+		kills := make(map[string]int)
+		for player := 0; player < 12; player++ {
+			kills[fmt.Sprintf("player%02d", player)] = (game*7 + player*3) % 11
+		}
+		reports = append(reports, reporter.GameReport{ID: fmt.Sprint(game), Kills: kills})
+	}
+
+	first := Compute(reports, DefaultConfig)
+	for i := 0; i < 50; i++ {
+		if again := Compute(reports, DefaultConfig); !reflect.DeepEqual(again, first) {
+			t.Fatalf("Expected replaying the same games to give the same ratings, run %d differs", i)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package reporter
+
+import (
+	"sort"
+	"time"
+)
+
+// DefaultMultiKillWindow is the longest gap between two kills of the same multi-kill when Options.MultiKillWindow is not set.
+const DefaultMultiKillWindow = 3 * time.Second
+
+// MinEndedStreak is the shortest streak listed in Awards.EndedStreaks.
+const MinEndedStreak = 3
+
+// ComputeAwards finds first blood, kill streaks and multi-kills in a game's kill log.
+// A streak counts kills of other players without dying; any death, including to <world> or a suicide, ends it.
+// A multi-kill is two or more kills by the same player, each at most window after the previous one.
+// It returns nil when the game has no kills.
+func ComputeAwards(kills []KillEntry, window time.Duration) *Awards {
+	if len(kills) == 0 {
+		return nil
+	}
+	if window <= 0 {
+		window = DefaultMultiKillWindow
+	}
+
+	awards := &Awards{
+		LongestStreaks: make(map[string]int),
+		MultiKills:     []MultiKill{},
+		EndedStreaks:   []EndedStreak{},
+	}
+	streaks := make(map[string]int)
+	chains := make(map[string]*MultiKill) // Multi-kill in progress of each player
+	lastKill := make(map[string]time.Duration)
+
+	for _, kill := range kills {
+		if kill.Killer != "<world>" && !kill.Suicide {
+			if awards.FirstBlood == nil {
+				awards.FirstBlood = &FirstBlood{Killer: kill.Killer, Victim: kill.Victim, Time: kill.Time}
+			}
+
+			streaks[kill.Killer]++
+			awards.LongestStreaks[kill.Killer] = max(awards.LongestStreaks[kill.Killer], streaks[kill.Killer])
+
+			at := time.Duration(kill.Seconds) * time.Second
+			last, seen := lastKill[kill.Killer]
+			lastKill[kill.Killer] = at
+			if chain := chains[kill.Killer]; chain != nil && seen && at-last <= window {
+				chain.Kills++
+				chain.End = kill.Time
+			} else {
+				awards.addMultiKill(chains[kill.Killer])
+				chains[kill.Killer] = &MultiKill{Player: kill.Killer, Kills: 1, Start: kill.Time, End: kill.Time, startTime: at}
+			}
+		}
+
+		if streak := streaks[kill.Victim]; streak >= MinEndedStreak {
+			awards.EndedStreaks = append(awards.EndedStreaks, EndedStreak{
+				Player:  kill.Victim,
+				Streak:  streak,
+				EndedBy: kill.Killer,
+				Time:    kill.Time,
+			})
+		}
+		streaks[kill.Victim] = 0
+		if _, ok := awards.LongestStreaks[kill.Victim]; !ok {
+			awards.LongestStreaks[kill.Victim] = 0
+		}
+	}
+
+	players := make([]string, 0, len(chains))
+	for player := range chains {
+		players = append(players, player)
+	}
+	sort.Strings(players)
+	for _, player := range players {
+		awards.addMultiKill(chains[player])
+	}
+	sort.SliceStable(awards.MultiKills, func(i, j int) bool {
+		return awards.MultiKills[i].startTime < awards.MultiKills[j].startTime
+	})
+	return awards
+}
+
+// addMultiKill records chain if it has at least two kills.
+func (a *Awards) addMultiKill(chain *MultiKill) {
+	if chain != nil && chain.Kills >= 2 {
+		a.MultiKills = append(a.MultiKills, *chain)
+	}
+}
@@ -0,0 +1,56 @@
+package reporter
+
+import (
+	"testing"
+	"time"
+
+	"quake_log_parser/parser"
+)
+
+func kill(seconds int, killer, victim string) KillEntry {
+	t := time.Duration(seconds) * time.Second
+	return KillEntry{Time: parser.FormatTimestamp(t), Seconds: seconds, Killer: killer, Victim: victim, Suicide: killer == victim}
+}
+
+func TestComputeAwards(t *testing.T) {
+	kills := []KillEntry{
+		kill(5, "<world>", "Mal"),
+		kill(10, "Zeh", "Mal"),
+		kill(12, "Zeh", "Isgalamido"),
+		kill(14, "Zeh", "Mal"),
+		kill(30, "Zeh", "Isgalamido"),
+		kill(31, "Isgalamido", "Zeh"),
+		kill(40, "Mal", "Mal"),
+		kill(50, "Isgalamido", "Mal"),
+	}
+
+	awards := ComputeAwards(kills, 0)
+	if awards.FirstBlood == nil || *awards.FirstBlood != (FirstBlood{Killer: "Zeh", Victim: "Mal", Time: "0:10"}) {
+		t.Errorf("Unexpected first blood: %+v", awards.FirstBlood)
+	}
+
+	expectedStreaks := map[string]int{"Zeh": 4, "Isgalamido": 2, "Mal": 0}
+	for player, streak := range expectedStreaks {
+		if awards.LongestStreaks[player] != streak {
+			t.Errorf("Expected %s's longest streak to be %d, got %d", player, streak, awards.LongestStreaks[player])
+		}
+	}
+
+	if len(awards.MultiKills) != 1 || awards.MultiKills[0] != (MultiKill{Player: "Zeh", Kills: 3, Start: "0:10", End: "0:14", startTime: 10 * time.Second}) {
+		t.Errorf("Unexpected multi-kills: %+v", awards.MultiKills)
+	}
+
+	if len(awards.EndedStreaks) != 1 || awards.EndedStreaks[0] != (EndedStreak{Player: "Zeh", Streak: 4, EndedBy: "Isgalamido", Time: "0:31"}) {
+		t.Errorf("Unexpected ended streaks: %+v", awards.EndedStreaks)
+	}
+
+	// With a 20s window Zeh's kill at 0:30 joins the burst and Isgalamido's two kills form a second one
+	wide := ComputeAwards(kills, 20*time.Second)
+	if len(wide.MultiKills) != 2 || wide.MultiKills[0].Kills != 4 || wide.MultiKills[1].Player != "Isgalamido" {
+		t.Errorf("Unexpected multi-kills with a 20s window: %+v", wide.MultiKills)
+	}
+
+	if ComputeAwards(nil, 0) != nil {
+		t.Errorf("Expected no awards for a game without kills")
+	}
+}
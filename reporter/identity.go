@@ -0,0 +1,28 @@
+package reporter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+)
+
+// gameIDBytes is how much of the SHA-256 GameID keeps: 64 bits, 16 hex digits.
+const gameIDBytes = 8
+
+// GameID returns the stored identity of the game at position ordinal (1-based) in the log identified by source,
+// whose lines hash to contentHash (see parser.Game.Hash).
+// The same game of the same source always gets the same ID, so storing a log again is idempotent,
+// while games of different sources never share one.
+func GameID(source string, ordinal int, contentHash string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%d\x00%s", source, ordinal, contentHash)))
+	return hex.EncodeToString(sum[:gameIDBytes])
+}
+
+// reGameID matches GameID results as well as the decimal IDs of games stored before IDs were hashes.
+var reGameID = regexp.MustCompile(`^[0-9a-f]{1,64}$`)
+
+// ValidGameID reports whether id has the format of a game ID.
+func ValidGameID(id string) bool {
+	return reGameID.MatchString(id)
+}
@@ -0,0 +1,51 @@
+package reporter
+
+import (
+	"testing"
+
+	"quake_log_parser/parser"
+)
+
+func TestGameID(t *testing.T) {
+	id := GameID("source-a", 1, "abc")
+	if len(id) != 16 || !ValidGameID(id) {
+		t.Fatalf("Expected a 16 digit hex ID, got %q", id)
+	}
+	if again := GameID("source-a", 1, "abc"); again != id {
+		t.Errorf("Expected the same inputs to give %q again, got %q", id, again)
+	}
+	for _, other := range []string{GameID("source-b", 1, "abc"), GameID("source-a", 2, "abc"), GameID("source-a", 1, "abd")} {
+		if other == id {
+			t.Errorf("Expected a different source, ordinal or hash to change the ID, got %q twice", id)
+		}
+	}
+}
+
+func TestValidGameID(t *testing.T) {
+	for _, id := range []string{"0123456789abcdef", "101", "99999"} {
+		if !ValidGameID(id) {
+			t.Errorf("Expected %q to be a valid game ID", id)
+		}
+	}
+	for _, id := range []string{"", "not-an-id", "0123456789ABCDEF", "12 3"} {
+		if ValidGameID(id) {
+			t.Errorf("Expected %q to be an invalid game ID", id)
+		}
+	}
+}
+
+func TestFormatGameData_Identity(t *testing.T) {
+	games := map[int]*parser.Game{
+		1: {ID: 1, Hash: "aaa"},
+		2: {ID: 2, Hash: "bbb"},
+	}
+	reports := FormatGameDataWithOptions(games, Options{Source: "upload"})
+	for ordinal, report := range reports {
+		if report.Ordinal != ordinal || report.Source != "upload" || report.Hash != games[ordinal].Hash {
+			t.Errorf("Game %d: expected ordinal, source and hash to be kept, got %d %q %q", ordinal, report.Ordinal, report.Source, report.Hash)
+		}
+		if expected := GameID("upload", ordinal, games[ordinal].Hash); report.ID != expected {
+			t.Errorf("Game %d: expected ID %q, got %q", ordinal, expected, report.ID)
+		}
+	}
+}
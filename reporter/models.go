@@ -1,5 +1,7 @@
 package reporter
 
+import "time"
+
 // RankedPlayer stores a player's name and their score for ranking.
 type RankedPlayer struct {
 	Name  string `json:"name" bson:"name"`
@@ -10,10 +12,264 @@ type RankedPlayer struct {
 // This includes the main report and the kills_by_means for the bonus.
 // It now includes BSON tags for MongoDB storage.
 type GameReport struct {
-	ID            int              `json:"id" bson:"_id"`
-	TotalKills    int              `json:"total_kills" bson:"total_kills"`
-	Players       []string         `json:"players" bson:"players"`
-	Kills         map[string]int   `json:"kills" bson:"kills"`
-	KillsByMeans  map[string]int   `json:"kills_by_means,omitempty" bson:"kills_by_means,omitempty"`
+	ID           string              `json:"id" bson:"_id"` // See GameID
+	TotalKills   int                 `json:"total_kills" bson:"total_kills"`
+	Players      []string            `json:"players" bson:"players"`
+	Kills        map[string]int      `json:"kills" bson:"kills"` // Net score: kills minus deaths by <world>, suicides and teamkills
+	KillsByMeans map[string]int      `json:"kills_by_means,omitempty" bson:"kills_by_means,omitempty"`
+	Aliases      map[string][]string `json:"aliases,omitempty" bson:"aliases,omitempty"` // Final player name -> earlier names used in the game
+
+	// Where the game came from
+	Source     string    `json:"source" bson:"source"`                           // Log the game was read from, e.g. the SHA-256 of the uploaded file
+	Ordinal    int       `json:"ordinal" bson:"ordinal"`                         // 1-based position of the game in its log
+	Hash       string    `json:"hash" bson:"hash"`                               // SHA-256 of the game's lines
+	UploadedAt time.Time `json:"uploaded_at" bson:"uploaded_at"`                 // When the game was first stored
+	UploadID   string    `json:"upload_id,omitempty" bson:"upload_id,omitempty"` // Upload that first stored the game
+
+	// KillsByMeans grouped and labelled using the means of death enum
+	KillsByCategory map[string]int `json:"kills_by_category,omitempty" bson:"kills_by_category,omitempty"` // direct, splash, melee, environmental, other
+	Means           []MeansSummary `json:"means,omitempty" bson:"means,omitempty"`                         // Most kills first
+
+	// Server settings the game was played with, taken from its InitGame line
+	Map          string            `json:"map" bson:"map"`
+	GameType     int               `json:"game_type" bson:"game_type"`
+	GameTypeName string            `json:"game_type_name" bson:"game_type_name"`
+	FragLimit    int               `json:"frag_limit" bson:"frag_limit"`
+	TimeLimit    int               `json:"time_limit" bson:"time_limit"` // Minutes
+	Hostname     string            `json:"hostname" bson:"hostname"`
+	Settings     map[string]string `json:"settings,omitempty" bson:"settings,omitempty"`
+
+	// How and when the game ended
+	StartTime       string `json:"start_time" bson:"start_time"`                       // m:ss, as in the log
+	EndTime         string `json:"end_time" bson:"end_time"`                           // m:ss, as in the log
+	DurationSeconds int    `json:"duration_seconds" bson:"duration_seconds"`           // Clock jumps in the log are not counted, see the clock_jump diagnostic
+	ExitReason      string `json:"exit_reason,omitempty" bson:"exit_reason,omitempty"` // e.g. "Fraglimit hit."
+	Aborted         bool   `json:"aborted" bson:"aborted"`                             // Ended without an Exit line
+
+	Diagnostics []Diagnostic `json:"diagnostics,omitempty" bson:"diagnostics,omitempty"` // Parse anomalies found in this game
+
+	// Server-reported final scoreboard, reconciled against the scores computed from the kill lines
+	Scoreboard         []ScoreboardEntry `json:"scoreboard,omitempty" bson:"scoreboard,omitempty"`
+	ScoreboardStatus   string            `json:"scoreboard_status,omitempty" bson:"scoreboard_status,omitempty"` // See the Scoreboard* constants, empty without a scoreboard
+	ScoreboardMismatch bool              `json:"scoreboard_mismatch" bson:"scoreboard_mismatch"`                 // ScoreboardStatus is ScoreboardMismatch
+
+	Teams *TeamsReport `json:"teams,omitempty" bson:"teams,omitempty"` // Only set for team gametypes
+
+	Items *ItemsReport `json:"items,omitempty" bson:"items,omitempty"` // Item pickups, nil when the game has none
+
+	Chat []ChatMessage `json:"chat,omitempty" bson:"chat,omitempty"` // say lines, in log order
+
+	KillMatrix map[string]map[string]int `json:"kill_matrix,omitempty" bson:"kill_matrix,omitempty"` // Killer -> victim -> times killed
+
+	PlayerStats map[string]PlayerStats `json:"player_stats,omitempty" bson:"player_stats,omitempty"` // Player name -> kill and death breakdown
+
+	KillLog []KillEntry `json:"kill_log,omitempty" bson:"kill_log,omitempty"` // Every kill, in log order
+
+	Awards          *Awards `json:"awards,omitempty" bson:"awards,omitempty"`                     // First blood, streaks and multi-kills, nil when the game has no kills
+	MultiKillWindow string  `json:"multikill_window,omitempty" bson:"multikill_window,omitempty"` // Go duration Awards found multi-kills with
+
+	Sessions map[string]PlayerSessions `json:"sessions,omitempty" bson:"sessions,omitempty"` // Player name -> connections during the game
 	// PlayerRanking []RankedPlayer `json:"player_ranking" bson:"player_ranking"` // Removed per-game ranking
-}
\ No newline at end of file
+}
+
+// Diagnostic is a parse anomaly as stored with a game report and returned by the API.
+type Diagnostic struct {
+	Line     int    `json:"line" bson:"line"` // 0 when not tied to a line (e.g. end of log)
+	Severity string `json:"severity" bson:"severity"`
+	Code     string `json:"code" bson:"code"`
+	Message  string `json:"message" bson:"message"`
+	Text     string `json:"text,omitempty" bson:"text,omitempty"`
+	GameID   int    `json:"game_id,omitempty" bson:"game_id,omitempty"`
+}
+
+// Values of GameReport.ScoreboardStatus.
+const (
+	ScoreboardMatch         = "match"          // Every entry agrees with the kill lines
+	ScoreboardMismatch      = "mismatch"       // At least one entry disagrees with the kill lines
+	ScoreboardNotComparable = "not_comparable" // Team gametype: server scores include points the kill lines do not carry (captures, assists, defends)
+)
+
+// ScoreboardEntry compares the server's final score for a client slot with the score computed from the kill lines.
+type ScoreboardEntry struct {
+	Name          string `json:"name" bson:"name"`
+	ClientID      int    `json:"client_id" bson:"client_id"`
+	Ping          int    `json:"ping" bson:"ping"`
+	ServerScore   int    `json:"server_score" bson:"server_score"`
+	ComputedScore int    `json:"computed_score" bson:"computed_score"` // Net score of the player in ClientID when the scoreboard was printed
+	Difference    int    `json:"difference" bson:"difference"`         // ServerScore - ComputedScore
+}
+
+// TeamsReport summarises a team game: final score, team totals and who played where.
+type TeamsReport struct {
+	Red     TeamReport                  `json:"red" bson:"red"`
+	Blue    TeamReport                  `json:"blue" bson:"blue"`
+	Winner  string                      `json:"winner,omitempty" bson:"winner,omitempty"`   // "red", "blue" or "draw"; empty without a final team score
+	History map[string][]TeamAssignment `json:"history,omitempty" bson:"history,omitempty"` // Player name -> team assignments in log order
+}
+
+// TeamReport holds the totals of one team.
+type TeamReport struct {
+	Score     *int     `json:"score,omitempty" bson:"score,omitempty"` // From the server's "red:N  blue:M" line
+	Players   []string `json:"players" bson:"players"`                 // Players who ended the game on this team
+	Kills     int      `json:"kills" bson:"kills"`                     // Enemies killed by the team's members
+	TeamKills int      `json:"team_kills" bson:"team_kills"`           // Teammates killed by the team's members
+}
+
+// TeamAssignment is a player joining a team.
+type TeamAssignment struct {
+	Time string `json:"time" bson:"time"` // m:ss, as in the log
+	Team string `json:"team" bson:"team"` // "red", "blue", "spectator" or "free"
+}
+
+// ItemsReport holds the item pickups of a game, for the game as a whole and per player.
+type ItemsReport struct {
+	ItemCounts `bson:",inline"`
+	Players    map[string]ItemCounts `json:"players" bson:"players"` // Player name -> their pickups
+}
+
+// ItemCounts counts pickups by item class (weapon, ammo, armor, health, powerup, ...) and by item.
+type ItemCounts struct {
+	Total   int            `json:"total" bson:"total"`
+	ByClass map[string]int `json:"by_class" bson:"by_class"`
+	ByItem  map[string]int `json:"by_item" bson:"by_item"`
+}
+
+// ChatMessage is a chat line sent during a game.
+type ChatMessage struct {
+	Time     string `json:"time" bson:"time"`                         // m:ss, as in the log
+	ClientID int    `json:"client_id" bson:"client_id"`               // -1 when the speaker could not be matched to a client slot
+	Name     string `json:"name" bson:"name"`                         // Name the message was sent under
+	Player   string `json:"player,omitempty" bson:"player,omitempty"` // Name the speaker ended the game with, as used in Kills
+	Text     string `json:"text" bson:"text"`
+}
+
+// PlayerSessions sums up how long a player was in a game.
+type PlayerSessions struct {
+	Reconnects    int       `json:"reconnects" bson:"reconnects"`
+	SecondsPlayed int       `json:"seconds_played" bson:"seconds_played"` // Time between ClientBegin and disconnect or game end, over all sessions
+	LeftEarly     bool      `json:"left_early" bson:"left_early"`         // The last session ended with a disconnect before the game ended
+	Sessions      []Session `json:"sessions" bson:"sessions"`
+}
+
+// Session is one connection of a player to the server. Times are m:ss, as in the log.
+type Session struct {
+	ClientID      int    `json:"client_id" bson:"client_id"`
+	Connect       string `json:"connect" bson:"connect"`
+	Begin         string `json:"begin,omitempty" bson:"begin,omitempty"`           // Empty if the client never entered the game
+	Disconnect    string `json:"disconnect,omitempty" bson:"disconnect,omitempty"` // Empty if the client was still connected at the end
+	SecondsPlayed int    `json:"seconds_played" bson:"seconds_played"`
+}
+
+// PlayerStats breaks a player's net score down into kills and deaths.
+type PlayerStats struct {
+	Score       int     `json:"score" bson:"score"`               // Net score, same as GameReport.Kills
+	Kills       int     `json:"kills" bson:"kills"`               // Other players killed
+	Deaths      int     `json:"deaths" bson:"deaths"`             // Deaths by any cause
+	WorldDeaths int     `json:"world_deaths" bson:"world_deaths"` // Deaths caused by <world>
+	Suicides    int     `json:"suicides" bson:"suicides"`         // Deaths by the player's own weapon
+	KDRatio     float64 `json:"kd_ratio" bson:"kd_ratio"`         // Kills / Deaths, or Kills when the player never died
+
+	KillsByMeans  map[string]int `json:"kills_by_means,omitempty" bson:"kills_by_means,omitempty"`   // Means of death -> players killed with it
+	DeathsByMeans map[string]int `json:"deaths_by_means,omitempty" bson:"deaths_by_means,omitempty"` // Means of death -> times the player died by it
+}
+
+// KillMatrixReport is the who-killed-whom matrix of a game with each player's rivalries.
+type KillMatrixReport struct {
+	Matrix    map[string]map[string]int `json:"matrix" bson:"matrix"`       // Killer -> victim -> times killed
+	Rivalries map[string]Rivalry        `json:"rivalries" bson:"rivalries"` // Player name -> rivalry
+}
+
+// Rivalry names the player someone killed most and the player who killed them most.
+// Ties go to the alphabetically first name.
+type Rivalry struct {
+	FavoriteVictim      string `json:"favorite_victim,omitempty" bson:"favorite_victim,omitempty"`
+	FavoriteVictimKills int    `json:"favorite_victim_kills" bson:"favorite_victim_kills"`
+	Nemesis             string `json:"nemesis,omitempty" bson:"nemesis,omitempty"`
+	NemesisKills        int    `json:"nemesis_kills" bson:"nemesis_kills"` // Times the nemesis killed the player
+}
+
+// MeansSummary describes one means of death used in a game.
+type MeansSummary struct {
+	Means    string `json:"means" bson:"means"`       // MOD_* name, as in KillsByMeans
+	Label    string `json:"label" bson:"label"`       // e.g. "Rocket Launcher (splash)"
+	Category string `json:"category" bson:"category"` // direct, splash, melee, environmental or other
+	Kills    int    `json:"kills" bson:"kills"`
+}
+
+// PlayerWeapons is a player's kills and deaths by means of death over every stored game.
+type PlayerWeapons struct {
+	Player     string        `json:"player"`
+	Games      int           `json:"games"`                 // Games the player appears in
+	MostLethal string        `json:"most_lethal,omitempty"` // Means the player killed the most players with
+	DiedMostTo string        `json:"died_most_to,omitempty"`
+	Weapons    []WeaponStats `json:"weapons"` // Most kills first, then most deaths, then by name
+}
+
+// WeaponStats holds a player's kills and deaths with one means of death.
+type WeaponStats struct {
+	Means    string `json:"means"`
+	Label    string `json:"label"`
+	Category string `json:"category"`
+	Kills    int    `json:"kills"`
+	Deaths   int    `json:"deaths"`
+}
+
+// Awards lists the notable moments of a game.
+type Awards struct {
+	FirstBlood     *FirstBlood    `json:"first_blood,omitempty" bson:"first_blood,omitempty"` // First player killed by another player
+	LongestStreaks map[string]int `json:"longest_streaks" bson:"longest_streaks"`             // Player name -> most kills in a row without dying
+	MultiKills     []MultiKill    `json:"multi_kills" bson:"multi_kills"`                     // In order of their first kill
+	EndedStreaks   []EndedStreak  `json:"ended_streaks" bson:"ended_streaks"`                 // Streaks of at least MinEndedStreak kills, in log order
+}
+
+// FirstBlood is the first kill of a game that was neither a suicide nor caused by <world>.
+type FirstBlood struct {
+	Killer string `json:"killer" bson:"killer"`
+	Victim string `json:"victim" bson:"victim"`
+	Time   string `json:"time" bson:"time"` // m:ss, as in the log
+}
+
+// MultiKill is a burst of kills by one player, each within the multi-kill window of the previous one.
+type MultiKill struct {
+	Player string `json:"player" bson:"player"`
+	Kills  int    `json:"kills" bson:"kills"`
+	Start  string `json:"start" bson:"start"` // m:ss of the first kill
+	End    string `json:"end" bson:"end"`     // m:ss of the last kill
+
+	startTime time.Duration // Since the game started
+}
+
+// EndedStreak is a kill streak and what ended it.
+type EndedStreak struct {
+	Player  string `json:"player" bson:"player"`
+	Streak  int    `json:"streak" bson:"streak"`
+	EndedBy string `json:"ended_by" bson:"ended_by"` // Killer's name, "<world>", or the player themselves for a suicide
+	Time    string `json:"time" bson:"time"`         // m:ss, as in the log
+}
+
+// KillEntry is one kill of a game, with players named as they ended the game.
+type KillEntry struct {
+	Time     string `json:"time" bson:"time"`       // m:ss, as in the log
+	Seconds  int    `json:"seconds" bson:"seconds"` // Seconds since the game started
+	Killer   string `json:"killer" bson:"killer"`   // "<world>" for environmental deaths
+	Victim   string `json:"victim" bson:"victim"`
+	Means    string `json:"means" bson:"means"`
+	Suicide  bool   `json:"suicide,omitempty" bson:"suicide,omitempty"`     // Victim killed themselves, told apart by client slot rather than name
+	TeamKill bool   `json:"team_kill,omitempty" bson:"team_kill,omitempty"` // Victim was the killer's teammate
+}
+
+// Timeline is a game's kills grouped into fixed-size time buckets.
+type Timeline struct {
+	BucketSeconds int              `json:"bucket_seconds"`
+	Buckets       []TimelineBucket `json:"buckets"`
+	ScoreLines    map[string][]int `json:"score_lines"` // Player name -> net score at the end of each bucket
+}
+
+// TimelineBucket counts the kills between Start and End seconds into the game.
+type TimelineBucket struct {
+	Start    int            `json:"start"` // Seconds since the game started, inclusive
+	End      int            `json:"end"`   // Seconds since the game started, exclusive
+	Kills    int            `json:"kills"`
+	ByPlayer map[string]int `json:"by_player"` // Killer -> other players killed, <world> and suicides left out
+	ByMeans  map[string]int `json:"by_means"`  // Means of death -> kills, every kill included
+}
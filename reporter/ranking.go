@@ -0,0 +1,175 @@
+package reporter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// RankingMetric selects what RankPlayers orders players by.
+type RankingMetric string
+
+const (
+	MetricScore          RankingMetric = "score" // Net score summed over games, as in GameReport.Kills (the default)
+	MetricKills          RankingMetric = "kills" // Other players killed
+	MetricKDRatio        RankingMetric = "kd"    // Kills / deaths over all games
+	MetricKillsPerMinute RankingMetric = "kpm"   // Kills per minute played
+	MetricWins           RankingMetric = "wins"  // Games won
+)
+
+// ParseRankingMetric converts a user supplied metric name into a RankingMetric. An empty string means MetricScore.
+func ParseRankingMetric(s string) (RankingMetric, error) {
+	switch m := RankingMetric(strings.ToLower(strings.TrimSpace(s))); m {
+	case "":
+		return MetricScore, nil
+	case MetricScore, MetricKills, MetricKDRatio, MetricKillsPerMinute, MetricWins:
+		return m, nil
+	}
+	return "", fmt.Errorf("unknown ranking metric %q (expected score, kills, kd, kpm or wins)", s)
+}
+
+// RankingOptions controls RankPlayers.
+type RankingOptions struct {
+	Metric   RankingMetric // Zero means MetricScore
+	MinGames int           // Players with fewer games are left out
+}
+
+// RankPlayers aggregates every player's stats over reports and ranks them by opts.Metric.
+//
+// Ties are broken, in order, by net score, kills, fewest deaths, most games and finally
+// by name, so the order never changes between calls. Players with the same metric value
+// share a rank and the next rank is skipped ("1, 2, 2, 4").
+//
+// A game is won by the player with the highest net score, every tied player included;
+// in team games with a final team score the winning team's players win instead.
+// Time played comes from the player's sessions, or the game's duration when there are none.
+func RankPlayers(reports []GameReport, opts RankingOptions) []PlayerRankEntry {
+	byName := make(map[string]*PlayerRankEntry)
+	entry := func(name string) *PlayerRankEntry {
+		if byName[name] == nil {
+			byName[name] = &PlayerRankEntry{PlayerName: name}
+		}
+		return byName[name]
+	}
+	for _, report := range reports {
+		winners := GameWinners(report)
+		for name, score := range report.Kills {
+			e := entry(name)
+			e.Games++
+			e.TotalKills += score
+			if stats, ok := report.PlayerStats[name]; ok {
+				e.Kills += stats.Kills
+				e.Deaths += stats.Deaths
+			}
+			e.SecondsPlayed += SecondsPlayed(report, name)
+			if winners[name] {
+				e.Wins++
+			}
+		}
+	}
+
+	totals := make([]PlayerRankEntry, 0, len(byName))
+	for _, e := range byName {
+		totals = append(totals, *e)
+	}
+	return RankTotals(totals, opts)
+}
+
+// RankTotals ranks players whose PlayerName, Games, TotalKills, Kills, Deaths, SecondsPlayed and Wins
+// are already summed over their games, as RankPlayers does. It fills in the other fields.
+func RankTotals(totals []PlayerRankEntry, opts RankingOptions) []PlayerRankEntry {
+	if opts.Metric == "" {
+		opts.Metric = MetricScore
+	}
+
+	ranking := make([]PlayerRankEntry, 0, len(totals))
+	for i := range totals {
+		e := &totals[i]
+		if e.Games < opts.MinGames {
+			continue
+		}
+		e.KDRatio = KDRatio(e.Kills, e.Deaths)
+		if e.SecondsPlayed > 0 {
+			e.KillsPerMinute = float64(e.Kills) / (float64(e.SecondsPlayed) / 60)
+		}
+		e.Metric = opts.Metric
+		e.Value = metricValue(*e, opts.Metric)
+		ranking = append(ranking, *e)
+	}
+
+	sort.Slice(ranking, func(i, j int) bool {
+		a, b := ranking[i], ranking[j]
+		switch {
+		case a.Value != b.Value:
+			return a.Value > b.Value
+		case a.TotalKills != b.TotalKills:
+			return a.TotalKills > b.TotalKills
+		case a.Kills != b.Kills:
+			return a.Kills > b.Kills
+		case a.Deaths != b.Deaths:
+			return a.Deaths < b.Deaths
+		case a.Games != b.Games:
+			return a.Games > b.Games
+		}
+		return a.PlayerName < b.PlayerName
+	})
+	for i := range ranking {
+		if i > 0 && ranking[i].Value == ranking[i-1].Value {
+			ranking[i].Rank = ranking[i-1].Rank
+		} else {
+			ranking[i].Rank = i + 1
+		}
+	}
+	return ranking
+}
+
+// metricValue returns e's value for metric.
+func metricValue(e PlayerRankEntry, metric RankingMetric) float64 {
+	switch metric {
+	case MetricKills:
+		return float64(e.Kills)
+	case MetricKDRatio:
+		return e.KDRatio
+	case MetricKillsPerMinute:
+		return e.KillsPerMinute
+	case MetricWins:
+		return float64(e.Wins)
+	}
+	return float64(e.TotalKills)
+}
+
+// SecondsPlayed is how long name played in report's game: the player's sessions, or the game's duration when there are none.
+func SecondsPlayed(report GameReport, name string) int {
+	if sessions, ok := report.Sessions[name]; ok {
+		return sessions.SecondsPlayed
+	}
+	return report.DurationSeconds
+}
+
+// GameWinners returns the names of the players who won report's game.
+func GameWinners(report GameReport) map[string]bool {
+	winners := make(map[string]bool)
+	if report.Teams != nil && (report.Teams.Winner == "red" || report.Teams.Winner == "blue") {
+		team := report.Teams.Red
+		if report.Teams.Winner == "blue" {
+			team = report.Teams.Blue
+		}
+		for _, name := range team.Players {
+			winners[name] = true
+		}
+		return winners
+	}
+
+	best, first := 0, true
+	for _, score := range report.Kills {
+		if first || score > best {
+			best, first = score, false
+		}
+	}
+	for name, score := range report.Kills {
+		if score == best {
+			winners[name] = true
+		}
+	}
+	return winners
+}
@@ -0,0 +1,105 @@
+package reporter
+
+import "testing"
+
+func rankingReports() []GameReport {
+	return []GameReport{
+		{
+			DurationSeconds: 600,
+			Kills:           map[string]int{"Zeh": 10, "Mal": 4, "Isgalamido": 10},
+			PlayerStats: map[string]PlayerStats{
+				"Zeh":        {Kills: 12, Deaths: 6},
+				"Mal":        {Kills: 5, Deaths: 10},
+				"Isgalamido": {Kills: 10, Deaths: 5},
+			},
+			Sessions: map[string]PlayerSessions{"Mal": {SecondsPlayed: 300}},
+		},
+		{
+			DurationSeconds: 300,
+			Kills:           map[string]int{"Zeh": 2, "Mal": 8},
+			PlayerStats: map[string]PlayerStats{
+				"Zeh": {Kills: 2, Deaths: 4},
+				"Mal": {Kills: 8, Deaths: 2},
+			},
+			Teams: &TeamsReport{Winner: "red", Red: TeamReport{Players: []string{"Zeh"}}, Blue: TeamReport{Players: []string{"Mal"}}},
+		},
+	}
+}
+
+func TestRankPlayers_Score(t *testing.T) {
+	ranking := RankPlayers(rankingReports(), RankingOptions{})
+
+	// Zeh and Mal are tied on net score and share rank 1; Zeh is listed first for having more kills
+	expected := []struct {
+		rank  int
+		name  string
+		value float64
+	}{
+		{rank: 1, name: "Zeh", value: 12},
+		{rank: 1, name: "Mal", value: 12},
+		{rank: 3, name: "Isgalamido", value: 10},
+	}
+	if len(ranking) != len(expected) {
+		t.Fatalf("Expected %d players, got %+v", len(expected), ranking)
+	}
+	for i, e := range expected {
+		got := ranking[i]
+		if got.Rank != e.rank || got.PlayerName != e.name || got.Value != e.value || got.Metric != MetricScore {
+			t.Errorf("Position %d: expected rank %d %s (%v), got rank %d %s (%v)", i, e.rank, e.name, e.value, got.Rank, got.PlayerName, got.Value)
+		}
+	}
+}
+
+func TestRankPlayers_Metrics(t *testing.T) {
+	reports := rankingReports()
+
+	wins := RankPlayers(reports, RankingOptions{Metric: MetricWins})
+	// Game 1 is shared by Zeh and Isgalamido, game 2 goes to the red team
+	if wins[0].PlayerName != "Zeh" || wins[0].Wins != 2 || wins[1].PlayerName != "Isgalamido" || wins[1].Wins != 1 || wins[2].Wins != 0 {
+		t.Errorf("Unexpected wins ranking: %+v", wins)
+	}
+
+	kpm := RankPlayers(reports, RankingOptions{Metric: MetricKillsPerMinute})
+	// Mal: 13 kills in 5+5 minutes; Zeh: 14 in 15; Isgalamido: 10 in 10
+	if kpm[0].PlayerName != "Mal" || kpm[0].Value != 1.3 || kpm[0].SecondsPlayed != 600 {
+		t.Errorf("Unexpected kills per minute ranking: %+v", kpm)
+	}
+
+	kd := RankPlayers(reports, RankingOptions{Metric: MetricKDRatio})
+	if kd[0].PlayerName != "Isgalamido" || kd[0].Value != 2 {
+		t.Errorf("Unexpected K/D ranking: %+v", kd)
+	}
+
+	minGames := RankPlayers(reports, RankingOptions{Metric: MetricKills, MinGames: 2})
+	if len(minGames) != 2 || minGames[0].PlayerName != "Zeh" || minGames[0].Rank != 1 || minGames[1].Rank != 2 {
+		t.Errorf("Unexpected ranking with min_games 2: %+v", minGames)
+	}
+}
+
+func TestRankPlayers_StableOrder(t *testing.T) {
+	reports := []GameReport{{Kills: map[string]int{"Zeh": 3, "Mal": 3, "Isgalamido": 3, "Chessus": 3}}}
+	for i := 0; i < 20; i++ {
+		ranking := RankPlayers(reports, RankingOptions{})
+		names := []string{ranking[0].PlayerName, ranking[1].PlayerName, ranking[2].PlayerName, ranking[3].PlayerName}
+		if names[0] != "Chessus" || names[1] != "Isgalamido" || names[2] != "Mal" || names[3] != "Zeh" {
+			t.Fatalf("Expected ties in name order, got %v", names)
+		}
+		for _, entry := range ranking {
+			if entry.Rank != 1 {
+				t.Fatalf("Expected every tied player to share rank 1, got %+v", ranking)
+			}
+		}
+	}
+}
+
+func TestParseRankingMetric(t *testing.T) {
+	if m, err := ParseRankingMetric(""); err != nil || m != MetricScore {
+		t.Errorf("Expected the default metric to be score, got %q, %v", m, err)
+	}
+	if m, err := ParseRankingMetric("KD"); err != nil || m != MetricKDRatio {
+		t.Errorf("Expected KD to parse as kd, got %q, %v", m, err)
+	}
+	if _, err := ParseRankingMetric("headshots"); err == nil {
+		t.Errorf("Expected an error for an unknown metric")
+	}
+}
@@ -3,39 +3,422 @@ package reporter
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"os"
 	"sort"
+	"time"
 
 	"quake_log_parser/parser" // Import the parser package
 )
 
+// Options tunes how FormatGameDataWithOptions builds reports.
+type Options struct {
+	// MultiKillWindow is the longest gap between two kills of the same multi-kill.
+	// Zero means DefaultMultiKillWindow.
+	MultiKillWindow time.Duration
+
+	// Source identifies the log the games are read from, e.g. the SHA-256 of an uploaded file.
+	// It is part of every report's ID, see GameID.
+	Source string
+}
+
 // FormatGameData converts the raw parsed game data into a map of GameReport structs,
 // suitable for JSON marshalling or database storage.
 // It now accepts map[int]*parser.Game and returns map[int]GameReport.
 func FormatGameData(games map[int]*parser.Game) map[int]GameReport {
+	return FormatGameDataWithOptions(games, Options{})
+}
+
+// FormatGameDataWithOptions is FormatGameData with tunable award detection and a log source for the game IDs.
+// The returned map is still keyed by each game's position in the log.
+func FormatGameDataWithOptions(games map[int]*parser.Game, opts Options) map[int]GameReport {
 	structuredGameReports := make(map[int]GameReport)
+	window := opts.MultiKillWindow
+	if window <= 0 {
+		window = DefaultMultiKillWindow
+	}
 
 	for gameID, parsedGameData := range games {
 		playerNames := make([]string, 0, len(parsedGameData.Players))
-		for name := range parsedGameData.Players {
-			if name != "<world>" { 
+		aliases := make(map[string][]string)
+		for name, player := range parsedGameData.Players {
+			if name != "<world>" {
 				playerNames = append(playerNames, name)
+				if len(player.Aliases) > 0 {
+					aliases[name] = player.Aliases
+				}
 			}
 		}
 		sort.Strings(playerNames)
 
+		scoreboard, scoreboardStatus := ReconcileScoreboard(parsedGameData)
+
 		report := GameReport{
-			ID:           gameID,
+			ID:           GameID(opts.Source, gameID, parsedGameData.Hash),
 			TotalKills:   parsedGameData.TotalKills,
 			Players:      playerNames,
 			Kills:        parsedGameData.KillsByPlayer,
 			KillsByMeans: parsedGameData.KillsByMeans,
+			Aliases:      aliases,
+
+			Source:  opts.Source,
+			Ordinal: gameID,
+			Hash:    parsedGameData.Hash,
+
+			KillsByCategory: KillsByCategory(parsedGameData.KillsByMeans),
+			Means:           SummarizeMeans(parsedGameData.KillsByMeans),
+
+			Map:          parsedGameData.Map,
+			GameType:     parsedGameData.GameType,
+			GameTypeName: parser.GameTypeName(parsedGameData.GameType),
+			FragLimit:    parsedGameData.FragLimit,
+			TimeLimit:    parsedGameData.TimeLimit,
+			Hostname:     parsedGameData.Hostname,
+			Settings:     parsedGameData.Settings,
+
+			StartTime:       parser.FormatTimestamp(parsedGameData.StartTime),
+			EndTime:         parser.FormatTimestamp(parsedGameData.EndTime),
+			DurationSeconds: int(parsedGameData.Duration.Seconds()),
+			ExitReason:      parsedGameData.ExitReason,
+			Aborted:         parsedGameData.Aborted,
+
+			Diagnostics: FormatDiagnostics(parsedGameData.Diagnostics),
+
+			Scoreboard:         scoreboard,
+			ScoreboardStatus:   scoreboardStatus,
+			ScoreboardMismatch: scoreboardStatus == ScoreboardMismatch,
+
+			Teams: FormatTeams(parsedGameData),
+			Items: FormatItems(parsedGameData),
+			Chat:  FormatChat(parsedGameData.Chat),
+
+			KillMatrix:  parsedGameData.KillMatrix,
+			PlayerStats: FormatPlayerStats(parsedGameData),
+			Sessions:    FormatSessions(parsedGameData),
+
+			KillLog: FormatKillLog(parsedGameData),
 		}
+		report.Awards, report.MultiKillWindow = ComputeAwards(report.KillLog, window), window.String()
 		structuredGameReports[gameID] = report
 	}
 	return structuredGameReports
 }
 
+// ReconcileScoreboard compares the server's final scoreboard with the scores computed from the kill lines,
+// matching rows by client slot so renamed and same-named players line up.
+// It returns one entry per scoreboard row and a Scoreboard* status, empty when the game has no scoreboard.
+// Team gametypes are ScoreboardNotComparable: the entries are kept, but the server counts more than frags.
+func ReconcileScoreboard(game *parser.Game) ([]ScoreboardEntry, string) {
+	if len(game.Scoreboard) == 0 {
+		return nil, ""
+	}
+	entries := make([]ScoreboardEntry, 0, len(game.Scoreboard))
+	status := ScoreboardMatch
+	for _, row := range game.Scoreboard {
+		entry := ScoreboardEntry{
+			Name:          row.Name,
+			ClientID:      row.ClientID,
+			Ping:          row.Ping,
+			ServerScore:   row.Score,
+			ComputedScore: row.ComputedScore,
+			Difference:    row.Score - row.ComputedScore,
+		}
+		if entry.Difference != 0 {
+			status = ScoreboardMismatch
+		}
+		entries = append(entries, entry)
+	}
+	if parser.IsTeamGame(game.GameType) {
+		status = ScoreboardNotComparable
+	}
+	return entries, status
+}
+
+// FormatTeams builds the teams section of a report. It returns nil unless game is a team gametype.
+func FormatTeams(game *parser.Game) *TeamsReport {
+	if !parser.IsTeamGame(game.GameType) {
+		return nil
+	}
+	teams := &TeamsReport{
+		Red:     TeamReport{Players: []string{}, Kills: game.KillsByTeam[parser.TeamRed], TeamKills: game.TeamKills[parser.TeamRed]},
+		Blue:    TeamReport{Players: []string{}, Kills: game.KillsByTeam[parser.TeamBlue], TeamKills: game.TeamKills[parser.TeamBlue]},
+		History: make(map[string][]TeamAssignment),
+	}
+	for name, player := range game.Players {
+		switch player.Team {
+		case parser.TeamRed:
+			teams.Red.Players = append(teams.Red.Players, name)
+		case parser.TeamBlue:
+			teams.Blue.Players = append(teams.Blue.Players, name)
+		}
+		for _, change := range player.TeamHistory {
+			teams.History[name] = append(teams.History[name], TeamAssignment{
+				Time: parser.FormatTimestamp(change.Time),
+				Team: parser.TeamName(change.Team),
+			})
+		}
+	}
+	sort.Strings(teams.Red.Players)
+	sort.Strings(teams.Blue.Players)
+
+	if score := game.TeamScore; score != nil {
+		red, blue := score.Red, score.Blue
+		teams.Red.Score, teams.Blue.Score = &red, &blue
+		switch {
+		case red > blue:
+			teams.Winner = "red"
+		case blue > red:
+			teams.Winner = "blue"
+		default:
+			teams.Winner = "draw"
+		}
+	}
+	return teams
+}
+
+// FormatItems builds the item pickup section of a report. It returns nil when nothing was picked up.
+// Pickups by clients that never announced a name count for the game but not for any player.
+func FormatItems(game *parser.Game) *ItemsReport {
+	if len(game.Items) == 0 {
+		return nil
+	}
+	items := &ItemsReport{
+		ItemCounts: countItems(game.Items),
+		Players:    make(map[string]ItemCounts),
+	}
+	for name, player := range game.Players {
+		if len(player.Items) > 0 {
+			items.Players[name] = countItems(player.Items)
+		}
+	}
+	return items
+}
+
+// countItems totals per-item pickup counts and groups them by parser.ItemClass.
+func countItems(pickups map[string]int) ItemCounts {
+	counts := ItemCounts{ByClass: make(map[string]int), ByItem: make(map[string]int, len(pickups))}
+	for item, count := range pickups {
+		counts.Total += count
+		counts.ByClass[parser.ItemClass(item)] += count
+		counts.ByItem[item] = count
+	}
+	return counts
+}
+
+// FormatChat converts parser chat messages into their report form.
+// It returns nil for an empty input so games without chat omit the field.
+func FormatChat(chat []parser.ChatMessage) []ChatMessage {
+	if len(chat) == 0 {
+		return nil
+	}
+	formatted := make([]ChatMessage, 0, len(chat))
+	for _, msg := range chat {
+		formatted = append(formatted, ChatMessage{
+			Time:     parser.FormatTimestamp(msg.Time),
+			ClientID: msg.ClientID,
+			Name:     msg.Name,
+			Player:   msg.Player,
+			Text:     msg.Text,
+		})
+	}
+	return formatted
+}
+
+// KillsByCategory groups kills by means of death into their categories (direct, splash, melee, environmental, other).
+func KillsByCategory(killsByMeans map[string]int) map[string]int {
+	if len(killsByMeans) == 0 {
+		return nil
+	}
+	byCategory := make(map[string]int)
+	for means, count := range killsByMeans {
+		byCategory[parser.MeansCategory(means)] += count
+	}
+	return byCategory
+}
+
+// SummarizeMeans labels and categorises each means of death, most kills first and then by name.
+func SummarizeMeans(killsByMeans map[string]int) []MeansSummary {
+	if len(killsByMeans) == 0 {
+		return nil
+	}
+	summary := make([]MeansSummary, 0, len(killsByMeans))
+	for means, count := range killsByMeans {
+		summary = append(summary, MeansSummary{
+			Means:    means,
+			Label:    parser.MeansLabel(means),
+			Category: parser.MeansCategory(means),
+			Kills:    count,
+		})
+	}
+	sort.Slice(summary, func(i, j int) bool {
+		if summary[i].Kills != summary[j].Kills {
+			return summary[i].Kills > summary[j].Kills
+		}
+		return summary[i].Means < summary[j].Means
+	})
+	return summary
+}
+
+// Rivalries derives each player's favourite victim and nemesis from a killer -> victim -> count matrix.
+// Every player in the matrix, as killer or victim, gets an entry.
+func Rivalries(matrix map[string]map[string]int) map[string]Rivalry {
+	rivalries := make(map[string]Rivalry)
+	for killer, victims := range matrix {
+		for victim, count := range victims {
+			k := rivalries[killer]
+			if count > k.FavoriteVictimKills || (count == k.FavoriteVictimKills && victim < k.FavoriteVictim) {
+				k.FavoriteVictim, k.FavoriteVictimKills = victim, count
+			}
+			rivalries[killer] = k
+
+			v := rivalries[victim]
+			if count > v.NemesisKills || (count == v.NemesisKills && killer < v.Nemesis) {
+				v.Nemesis, v.NemesisKills = killer, count
+			}
+			rivalries[victim] = v
+		}
+	}
+	return rivalries
+}
+
+// FormatPlayerStats builds the per-player kill and death breakdown of a report.
+func FormatPlayerStats(game *parser.Game) map[string]PlayerStats {
+	if len(game.Players) == 0 {
+		return nil
+	}
+	stats := make(map[string]PlayerStats, len(game.Players))
+	for name, player := range game.Players {
+		stats[name] = PlayerStats{
+			Score:       player.Score,
+			Kills:       player.Kills,
+			Deaths:      player.Deaths,
+			WorldDeaths: player.WorldDeaths,
+			Suicides:    player.Suicides,
+			KDRatio:     KDRatio(player.Kills, player.Deaths),
+
+			KillsByMeans:  player.KillsByMeans,
+			DeathsByMeans: player.DeathsByMeans,
+		}
+	}
+	return stats
+}
+
+// AggregateWeapons sums name's kills and deaths by means of death over reports.
+// Games are matched on the name the player ended each game with.
+func AggregateWeapons(name string, reports []GameReport) PlayerWeapons {
+	weapons := PlayerWeapons{Player: name, Weapons: []WeaponStats{}}
+	byMeans := make(map[string]*WeaponStats)
+	stat := func(means string) *WeaponStats {
+		if byMeans[means] == nil {
+			byMeans[means] = &WeaponStats{Means: means, Label: parser.MeansLabel(means), Category: parser.MeansCategory(means)}
+		}
+		return byMeans[means]
+	}
+	for _, report := range reports {
+		stats, ok := report.PlayerStats[name]
+		if !ok {
+			continue
+		}
+		weapons.Games++
+		for means, count := range stats.KillsByMeans {
+			stat(means).Kills += count
+		}
+		for means, count := range stats.DeathsByMeans {
+			stat(means).Deaths += count
+		}
+	}
+
+	for _, s := range byMeans {
+		weapons.Weapons = append(weapons.Weapons, *s)
+	}
+	sort.Slice(weapons.Weapons, func(i, j int) bool {
+		a, b := weapons.Weapons[i], weapons.Weapons[j]
+		if a.Kills != b.Kills {
+			return a.Kills > b.Kills
+		}
+		if a.Deaths != b.Deaths {
+			return a.Deaths > b.Deaths
+		}
+		return a.Means < b.Means
+	})
+
+	mostKills, mostDeaths := 0, 0
+	for _, w := range weapons.Weapons {
+		if w.Kills > mostKills {
+			weapons.MostLethal, mostKills = w.Means, w.Kills
+		}
+		if w.Deaths > mostDeaths || (w.Deaths == mostDeaths && w.Deaths > 0 && w.Means < weapons.DiedMostTo) {
+			weapons.DiedMostTo, mostDeaths = w.Means, w.Deaths
+		}
+	}
+	return weapons
+}
+
+// KDRatio returns kills per death rounded to two decimals. A player who never died gets their kill count.
+func KDRatio(kills, deaths int) float64 {
+	if deaths == 0 {
+		return float64(kills)
+	}
+	return math.Round(float64(kills)/float64(deaths)*100) / 100
+}
+
+// FormatSessions builds the per-player session section of a report.
+// It returns nil when no player has a session, e.g. for logs without connect lines.
+func FormatSessions(game *parser.Game) map[string]PlayerSessions {
+	sessions := make(map[string]PlayerSessions)
+	for name, player := range game.Players {
+		if len(player.Sessions) == 0 {
+			continue
+		}
+		summary := PlayerSessions{
+			Reconnects:    player.Reconnects,
+			SecondsPlayed: int(player.TimePlayed.Seconds()),
+			Sessions:      make([]Session, 0, len(player.Sessions)),
+		}
+		for _, s := range player.Sessions {
+			session := Session{
+				ClientID:      s.ClientID,
+				Connect:       parser.FormatTimestamp(s.ConnectTime),
+				SecondsPlayed: int(s.TimePlayed.Seconds()),
+			}
+			if s.Began {
+				session.Begin = parser.FormatTimestamp(s.BeginTime)
+			}
+			if s.Disconnected {
+				session.Disconnect = parser.FormatTimestamp(s.DisconnectTime)
+			}
+			summary.Sessions = append(summary.Sessions, session)
+		}
+		last := player.Sessions[len(player.Sessions)-1]
+		summary.LeftEarly = last.Disconnected && last.DisconnectTime < game.EndTime
+		sessions[name] = summary
+	}
+	if len(sessions) == 0 {
+		return nil
+	}
+	return sessions
+}
+
+// FormatDiagnostics converts parser diagnostics into their report form.
+// It returns nil for an empty input so reports without anomalies omit the field.
+func FormatDiagnostics(diagnostics []parser.Diagnostic) []Diagnostic {
+	if len(diagnostics) == 0 {
+		return nil
+	}
+	formatted := make([]Diagnostic, 0, len(diagnostics))
+	for _, d := range diagnostics {
+		formatted = append(formatted, Diagnostic{
+			Line:     d.Line,
+			Severity: string(d.Severity),
+			Code:     string(d.Code),
+			Message:  d.Message,
+			Text:     d.Text,
+			GameID:   d.GameID,
+		})
+	}
+	return formatted
+}
+
 // PrintGameReportsToConsole takes the formatted game reports and prints them to standard output as JSON.
 // It now accepts map[int]GameReport.
 func PrintGameReportsToConsole(reports map[int]GameReport) {
@@ -61,6 +444,18 @@ func PrintGameReportsToConsole(reports map[int]GameReport) {
 // PlayerRankEntry defines the structure for a player's entry in the global ranking.
 // This is used by the /playersranking endpoint.
 type PlayerRankEntry struct {
+	Rank       int    `json:"rank"` // Players with the same metric value share a rank
 	PlayerName string `json:"player_name"`
-	TotalKills int    `json:"total_kills"`
-} 
\ No newline at end of file
+	TotalKills int    `json:"total_kills"` // Net score summed over games
+
+	Metric RankingMetric `json:"metric"`
+	Value  float64       `json:"value"` // The player's value for Metric
+
+	Games          int     `json:"games"`
+	Wins           int     `json:"wins"`
+	Kills          int     `json:"kills"`
+	Deaths         int     `json:"deaths"`
+	KDRatio        float64 `json:"kd_ratio"`
+	SecondsPlayed  int     `json:"seconds_played"`
+	KillsPerMinute float64 `json:"kills_per_minute"`
+}
@@ -0,0 +1,208 @@
+package reporter
+
+import (
+	"testing"
+
+	"quake_log_parser/parser"
+)
+
+func TestReconcileScoreboard(t *testing.T) {
+	game := &parser.Game{
+		GameType: parser.GameTypeFFA,
+		Scoreboard: []parser.ScoreEntry{
+			{Score: 20, Ping: 4, ClientID: 4, Name: "Zeh", ComputedScore: 20},
+			{Score: 12, Ping: 0, ClientID: 2, Name: "Isgalamido", ComputedScore: 10},
+			{Score: 3, Ping: 50, ClientID: 5, Name: "Mal"},
+		},
+	}
+
+	entries, status := ReconcileScoreboard(game)
+	if status != ScoreboardMismatch {
+		t.Errorf("Expected a mismatch to be flagged, got %q", status)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %d", len(entries))
+	}
+	expectedDifferences := map[string]int{"Zeh": 0, "Isgalamido": 2, "Mal": 3}
+	for _, entry := range entries {
+		if entry.Difference != expectedDifferences[entry.Name] {
+			t.Errorf("Expected %s to differ by %d, got %d", entry.Name, expectedDifferences[entry.Name], entry.Difference)
+		}
+	}
+
+	game.Scoreboard = game.Scoreboard[:1]
+	entries, status = ReconcileScoreboard(game)
+	if status != ScoreboardMatch || entries[0].ComputedScore != 20 {
+		t.Errorf("Expected a matching scoreboard, got %+v (%q)", entries, status)
+	}
+
+	// Capture points make a CTF scoreboard differ from frags
+	ctf := &parser.Game{
+		GameType:   parser.GameTypeCTF,
+		Scoreboard: []parser.ScoreEntry{{Score: 25, ClientID: 2, Name: "Isgalamido", ComputedScore: 5}},
+	}
+	if entries, status := ReconcileScoreboard(ctf); status != ScoreboardNotComparable || len(entries) != 1 || entries[0].Difference != 20 {
+		t.Errorf("Expected a not comparable CTF scoreboard, got %+v (%q)", entries, status)
+	}
+
+	if entries, status := ReconcileScoreboard(&parser.Game{}); entries != nil || status != "" {
+		t.Errorf("Expected no entries for an empty scoreboard, got %+v (%q)", entries, status)
+	}
+}
+
+func TestFormatTeams(t *testing.T) {
+	ffa := &parser.Game{GameType: parser.GameTypeFFA}
+	if teams := FormatTeams(ffa); teams != nil {
+		t.Errorf("Expected no teams section for a free for all game, got %+v", teams)
+	}
+
+	ctf := &parser.Game{
+		GameType:    parser.GameTypeCTF,
+		TeamScore:   &parser.TeamScore{Red: 2, Blue: 8},
+		KillsByTeam: map[int]int{parser.TeamRed: 5, parser.TeamBlue: 7},
+		TeamKills:   map[int]int{parser.TeamBlue: 1},
+		Players: map[string]*parser.Player{
+			"Zeh":        {Name: "Zeh", Team: parser.TeamBlue, TeamHistory: []parser.TeamChange{{Team: parser.TeamBlue}}},
+			"Isgalamido": {Name: "Isgalamido", Team: parser.TeamRed, TeamHistory: []parser.TeamChange{{Team: parser.TeamRed}}},
+			"Mal":        {Name: "Mal", Team: parser.TeamSpectator, TeamHistory: []parser.TeamChange{{Team: parser.TeamSpectator}}},
+		},
+	}
+	teams := FormatTeams(ctf)
+	if teams == nil {
+		t.Fatalf("Expected a teams section for a CTF game")
+	}
+	if teams.Winner != "blue" || *teams.Red.Score != 2 || *teams.Blue.Score != 8 {
+		t.Errorf("Unexpected final score: winner %q, red %d, blue %d", teams.Winner, *teams.Red.Score, *teams.Blue.Score)
+	}
+	if len(teams.Red.Players) != 1 || teams.Red.Players[0] != "Isgalamido" || len(teams.Blue.Players) != 1 || teams.Blue.Players[0] != "Zeh" {
+		t.Errorf("Unexpected rosters: red %v, blue %v", teams.Red.Players, teams.Blue.Players)
+	}
+	if teams.Blue.Kills != 7 || teams.Blue.TeamKills != 1 || teams.Red.Kills != 5 {
+		t.Errorf("Unexpected team totals: red %+v, blue %+v", teams.Red, teams.Blue)
+	}
+	if history := teams.History["Mal"]; len(history) != 1 || history[0].Team != "spectator" {
+		t.Errorf("Unexpected history for Mal: %+v", history)
+	}
+}
+
+func TestFormatItems(t *testing.T) {
+	if items := FormatItems(&parser.Game{}); items != nil {
+		t.Errorf("Expected no items section for a game without pickups, got %+v", items)
+	}
+
+	game := &parser.Game{
+		Items: map[string]int{"weapon_railgun": 2, "item_armor_shard": 3, "item_armor_body": 1},
+		Players: map[string]*parser.Player{
+			"Zeh": {Name: "Zeh", Items: map[string]int{"item_armor_shard": 3, "item_armor_body": 1}},
+			"Mal": {Name: "Mal"},
+		},
+	}
+	items := FormatItems(game)
+	if items.Total != 6 || items.ByClass["armor"] != 4 || items.ByClass["weapon"] != 2 {
+		t.Errorf("Unexpected game totals: %+v", items.ItemCounts)
+	}
+	zeh, ok := items.Players["Zeh"]
+	if !ok || zeh.Total != 4 || zeh.ByClass["armor"] != 4 || zeh.ByItem["item_armor_body"] != 1 {
+		t.Errorf("Unexpected pickups for Zeh: %+v", zeh)
+	}
+	if _, ok := items.Players["Mal"]; ok {
+		t.Errorf("Expected players without pickups to be left out")
+	}
+}
+
+func TestKDRatio(t *testing.T) {
+	cases := []struct {
+		kills, deaths int
+		expected      float64
+	}{
+		{kills: 10, deaths: 4, expected: 2.5},
+		{kills: 2, deaths: 3, expected: 0.67},
+		{kills: 5, deaths: 0, expected: 5},
+		{kills: 0, deaths: 0, expected: 0},
+	}
+	for _, tc := range cases {
+		if got := KDRatio(tc.kills, tc.deaths); got != tc.expected {
+			t.Errorf("KDRatio(%d, %d): expected %v, got %v", tc.kills, tc.deaths, tc.expected, got)
+		}
+	}
+}
+
+func TestRivalries(t *testing.T) {
+	matrix := map[string]map[string]int{
+		"Zeh":        {"Isgalamido": 3, "Mal": 3},
+		"Isgalamido": {"Zeh": 1, "Mal": 5},
+		"Mal":        {"Zeh": 1},
+	}
+	rivalries := Rivalries(matrix)
+
+	expected := map[string]Rivalry{
+		"Zeh":        {FavoriteVictim: "Isgalamido", FavoriteVictimKills: 3, Nemesis: "Isgalamido", NemesisKills: 1},
+		"Isgalamido": {FavoriteVictim: "Mal", FavoriteVictimKills: 5, Nemesis: "Zeh", NemesisKills: 3},
+		"Mal":        {FavoriteVictim: "Zeh", FavoriteVictimKills: 1, Nemesis: "Isgalamido", NemesisKills: 5},
+	}
+	for name, rivalry := range expected {
+		if rivalries[name] != rivalry {
+			t.Errorf("Rivalry for %s: expected %+v, got %+v", name, rivalry, rivalries[name])
+		}
+	}
+}
+
+func TestAggregateWeapons(t *testing.T) {
+	reports := []GameReport{
+		{PlayerStats: map[string]PlayerStats{
+			"Zeh": {KillsByMeans: map[string]int{"MOD_ROCKET": 4, "MOD_RAILGUN": 1}, DeathsByMeans: map[string]int{"MOD_RAILGUN": 2}},
+		}},
+		{PlayerStats: map[string]PlayerStats{
+			"Zeh": {KillsByMeans: map[string]int{"MOD_RAILGUN": 2}, DeathsByMeans: map[string]int{"MOD_TRIGGER_HURT": 3}},
+			"Mal": {KillsByMeans: map[string]int{"MOD_SHOTGUN": 9}},
+		}},
+		{PlayerStats: map[string]PlayerStats{"Mal": {}}},
+	}
+
+	weapons := AggregateWeapons("Zeh", reports)
+	if weapons.Games != 2 || weapons.MostLethal != "MOD_ROCKET" || weapons.DiedMostTo != "MOD_TRIGGER_HURT" {
+		t.Errorf("Unexpected summary: %+v", weapons)
+	}
+	expected := []WeaponStats{
+		{Means: "MOD_ROCKET", Label: "Rocket Launcher", Category: "direct", Kills: 4},
+		{Means: "MOD_RAILGUN", Label: "Railgun", Category: "direct", Kills: 3, Deaths: 2},
+		{Means: "MOD_TRIGGER_HURT", Label: "Map Hazard", Category: "environmental", Deaths: 3},
+	}
+	if len(weapons.Weapons) != len(expected) {
+		t.Fatalf("Expected %d weapons, got %+v", len(expected), weapons.Weapons)
+	}
+	for i, w := range expected {
+		if weapons.Weapons[i] != w {
+			t.Errorf("Weapon %d: expected %+v, got %+v", i, w, weapons.Weapons[i])
+		}
+	}
+
+	if unknown := AggregateWeapons("Nobody", reports); unknown.Games != 0 || len(unknown.Weapons) != 0 {
+		t.Errorf("Expected nothing for an unknown player, got %+v", unknown)
+	}
+}
+
+func TestSummarizeMeans(t *testing.T) {
+	killsByMeans := map[string]int{"MOD_ROCKET_SPLASH": 5, "MOD_TRIGGER_HURT": 5, "MOD_RAILGUN": 2, "MOD_GRENADE_SPLASH": 1}
+
+	summary := SummarizeMeans(killsByMeans)
+	expected := []MeansSummary{
+		{Means: "MOD_ROCKET_SPLASH", Label: "Rocket Launcher (splash)", Category: "splash", Kills: 5},
+		{Means: "MOD_TRIGGER_HURT", Label: "Map Hazard", Category: "environmental", Kills: 5},
+		{Means: "MOD_RAILGUN", Label: "Railgun", Category: "direct", Kills: 2},
+		{Means: "MOD_GRENADE_SPLASH", Label: "Grenade Launcher (splash)", Category: "splash", Kills: 1},
+	}
+	if len(summary) != len(expected) {
+		t.Fatalf("Expected %d entries, got %+v", len(expected), summary)
+	}
+	for i, e := range expected {
+		if summary[i] != e {
+			t.Errorf("Entry %d: expected %+v, got %+v", i, e, summary[i])
+		}
+	}
+
+	byCategory := KillsByCategory(killsByMeans)
+	if byCategory["splash"] != 6 || byCategory["environmental"] != 5 || byCategory["direct"] != 2 {
+		t.Errorf("Unexpected kills by category: %v", byCategory)
+	}
+}
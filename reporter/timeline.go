@@ -0,0 +1,102 @@
+package reporter
+
+import (
+	"fmt"
+	"time"
+
+	"quake_log_parser/parser"
+)
+
+// MaxTimelineBuckets caps the number of buckets BuildTimeline returns, so a tiny bucket on a long game cannot blow up a response.
+const MaxTimelineBuckets = 10000
+
+// FormatKillLog converts a game's kills into their report form, timed from the start of the game.
+// It returns nil when the game has no kills.
+func FormatKillLog(game *parser.Game) []KillEntry {
+	if len(game.Kills) == 0 {
+		return nil
+	}
+	entries := make([]KillEntry, 0, len(game.Kills))
+	for _, kill := range game.Kills {
+		entries = append(entries, KillEntry{
+			Time:     parser.FormatTimestamp(kill.Time),
+			Seconds:  int(max(kill.Time-game.StartTime, 0) / time.Second),
+			Killer:   kill.Killer,
+			Victim:   kill.Victim,
+			Means:    kill.Means,
+			Suicide:  kill.IsSuicide(),
+			TeamKill: kill.TeamKill,
+		})
+	}
+	return entries
+}
+
+// BuildTimeline groups a kill log into buckets of the given size, covering the game from its start
+// to durationSeconds (or the last kill, if later). Empty buckets are kept so the result plots as is.
+// Score lines follow the player Score rule: +1 for a kill, -1 for a teamkill, and -1 to the victim
+// for dying to <world> or a suicide.
+func BuildTimeline(kills []KillEntry, durationSeconds int, bucket time.Duration) (*Timeline, error) {
+	bucketSeconds := int(bucket / time.Second)
+	if bucketSeconds < 1 || bucket%time.Second != 0 {
+		return nil, fmt.Errorf("bucket must be a whole number of seconds, got %v", bucket)
+	}
+
+	end := durationSeconds
+	for _, kill := range kills {
+		end = max(end, kill.Seconds+1)
+	}
+	count := max((end+bucketSeconds-1)/bucketSeconds, 1)
+	if count > MaxTimelineBuckets {
+		return nil, fmt.Errorf("bucket %v splits the game into %d buckets, more than the %d allowed", bucket, count, MaxTimelineBuckets)
+	}
+
+	timeline := &Timeline{
+		BucketSeconds: bucketSeconds,
+		Buckets:       make([]TimelineBucket, count),
+		ScoreLines:    make(map[string][]int),
+	}
+	for i := range timeline.Buckets {
+		timeline.Buckets[i] = TimelineBucket{
+			Start:    i * bucketSeconds,
+			End:      (i + 1) * bucketSeconds,
+			ByPlayer: make(map[string]int),
+			ByMeans:  make(map[string]int),
+		}
+	}
+
+	// Score changes per bucket, turned into running totals below
+	deltas := make(map[string][]int)
+	addScore := func(player string, i, delta int) {
+		if deltas[player] == nil {
+			deltas[player] = make([]int, count)
+		}
+		deltas[player][i] += delta
+	}
+	for _, kill := range kills {
+		i := kill.Seconds / bucketSeconds
+		b := &timeline.Buckets[i]
+		b.Kills++
+		b.ByMeans[kill.Means]++
+		if kill.Killer == "<world>" || kill.Suicide {
+			addScore(kill.Victim, i, -1)
+			continue
+		}
+		b.ByPlayer[kill.Killer]++
+		if kill.TeamKill {
+			addScore(kill.Killer, i, -1)
+		} else {
+			addScore(kill.Killer, i, 1)
+		}
+		addScore(kill.Victim, i, 0)
+	}
+	for player, playerDeltas := range deltas {
+		line := make([]int, count)
+		total := 0
+		for i, delta := range playerDeltas {
+			total += delta
+			line[i] = total
+		}
+		timeline.ScoreLines[player] = line
+	}
+	return timeline, nil
+}
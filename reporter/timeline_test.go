@@ -0,0 +1,109 @@
+package reporter
+
+import (
+	"testing"
+	"time"
+
+	"quake_log_parser/parser"
+)
+
+func TestFormatKillLog(t *testing.T) {
+	game := &parser.Game{
+		StartTime: 20 * time.Minute,
+		Kills: []parser.Kill{
+			{Time: 20*time.Minute + 5*time.Second, KillerID: 2, VictimID: 3, Killer: "Zeh", Victim: "Mal", Means: "MOD_RAILGUN"},
+			{Time: 20*time.Minute + 9*time.Second, KillerID: 3, VictimID: 3, Killer: "Mal", Victim: "Mal", Means: "MOD_ROCKET_SPLASH"},
+		},
+	}
+	log := FormatKillLog(game)
+	expected := []KillEntry{
+		{Time: "20:05", Seconds: 5, Killer: "Zeh", Victim: "Mal", Means: "MOD_RAILGUN"},
+		{Time: "20:09", Seconds: 9, Killer: "Mal", Victim: "Mal", Means: "MOD_ROCKET_SPLASH", Suicide: true},
+	}
+	if len(log) != 2 || log[0] != expected[0] || log[1] != expected[1] {
+		t.Errorf("Expected %+v, got %+v", expected, log)
+	}
+	if FormatKillLog(&parser.Game{}) != nil {
+		t.Errorf("Expected no kill log for a game without kills")
+	}
+}
+
+func TestBuildTimeline(t *testing.T) {
+	kills := []KillEntry{
+		{Seconds: 5, Killer: "Zeh", Victim: "Mal", Means: "MOD_RAILGUN"},
+		{Seconds: 20, Killer: "Zeh", Victim: "Mal", Means: "MOD_RAILGUN"},
+		{Seconds: 35, Killer: "Mal", Victim: "Zeh", Means: "MOD_ROCKET"},
+		{Seconds: 40, Killer: "<world>", Victim: "Zeh", Means: "MOD_FALLING"},
+		{Seconds: 65, Killer: "Mal", Victim: "Mal", Means: "MOD_ROCKET_SPLASH", Suicide: true},
+	}
+
+	timeline, err := BuildTimeline(kills, 90, 30*time.Second)
+	if err != nil {
+		t.Fatalf("BuildTimeline returned an error: %v", err)
+	}
+	if timeline.BucketSeconds != 30 || len(timeline.Buckets) != 3 {
+		t.Fatalf("Expected 3 buckets of 30s, got %d of %ds", len(timeline.Buckets), timeline.BucketSeconds)
+	}
+
+	first := timeline.Buckets[0]
+	if first.Start != 0 || first.End != 30 || first.Kills != 2 || first.ByPlayer["Zeh"] != 2 || first.ByMeans["MOD_RAILGUN"] != 2 {
+		t.Errorf("Unexpected first bucket: %+v", first)
+	}
+	second := timeline.Buckets[1]
+	if second.Kills != 2 || second.ByPlayer["Mal"] != 1 || len(second.ByPlayer) != 1 || second.ByMeans["MOD_FALLING"] != 1 {
+		t.Errorf("Unexpected second bucket: %+v", second)
+	}
+	if third := timeline.Buckets[2]; third.Kills != 1 || len(third.ByPlayer) != 0 {
+		t.Errorf("Expected only the suicide in the last bucket, got %+v", third)
+	}
+
+	expectedLines := map[string][]int{"Zeh": {2, 1, 1}, "Mal": {0, 1, 0}}
+	for player, line := range expectedLines {
+		got := timeline.ScoreLines[player]
+		if len(got) != len(line) {
+			t.Fatalf("Expected %d points for %s, got %v", len(line), player, got)
+		}
+		for i := range line {
+			if got[i] != line[i] {
+				t.Errorf("Score line of %s: expected %v, got %v", player, line, got)
+				break
+			}
+		}
+	}
+
+	// A teamkill still counts as a kill in the bucket but costs the killer a point
+	teamKills := []KillEntry{
+		{Seconds: 5, Killer: "Zeh", Victim: "Mal", Means: "MOD_RAILGUN"},
+		{Seconds: 10, Killer: "Zeh", Victim: "Dono", Means: "MOD_RAILGUN", TeamKill: true},
+	}
+	timeline, err = BuildTimeline(teamKills, 30, 30*time.Second)
+	if err != nil {
+		t.Fatalf("BuildTimeline returned an error: %v", err)
+	}
+	if b := timeline.Buckets[0]; b.Kills != 2 || b.ByPlayer["Zeh"] != 2 {
+		t.Errorf("Expected both kills in the bucket, got %+v", b)
+	}
+	if got := timeline.ScoreLines["Zeh"]; len(got) != 1 || got[0] != 0 {
+		t.Errorf("Expected Zeh's score line to be [0], got %v", got)
+	}
+
+	// Two slots that ended the game under the same name: a kill, not a suicide
+	sameName := []KillEntry{{Seconds: 5, Killer: "B", Victim: "B", Means: "MOD_RAILGUN"}}
+	timeline, err = BuildTimeline(sameName, 30, 30*time.Second)
+	if err != nil {
+		t.Fatalf("BuildTimeline returned an error: %v", err)
+	}
+	if b := timeline.Buckets[0]; b.ByPlayer["B"] != 1 {
+		t.Errorf("Expected the kill to count for B, got %+v", b)
+	}
+	if got := timeline.ScoreLines["B"]; len(got) != 1 || got[0] != 1 {
+		t.Errorf("Expected B's score line to be [1], got %v", got)
+	}
+
+	if _, err := BuildTimeline(kills, 90, 1500*time.Millisecond); err == nil {
+		t.Errorf("Expected an error for a bucket that is not a whole number of seconds")
+	}
+	if _, err := BuildTimeline(kills, 60*60*24, time.Second); err == nil {
+		t.Errorf("Expected an error when the bucket makes too many buckets")
+	}
+}
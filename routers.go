@@ -2,30 +2,33 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
-	"os"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
-	swaggerFiles "github.com/swaggo/files" // swagger embed files
+	swaggerFiles "github.com/swaggo/files"     // swagger embed files
 	ginSwagger "github.com/swaggo/gin-swagger" // gin-swagger middleware
 
-	"go.mongodb.org/mongo-driver/mongo"
 	"quake_log_parser/database"
 	_ "quake_log_parser/docs" // docs is generated by Swag CLI, you need to import it.
 	"quake_log_parser/parser"
+	"quake_log_parser/rating"
 	"quake_log_parser/reporter"
 )
 
 // SetupRouter initializes and configures the Gin router with all API endpoints.
-// It takes the MongoDB collection for game reports as an argument.
-func SetupRouter(gameCollection *mongo.Collection) *gin.Engine {
+// It takes the GameStore the game reports and ratings are kept in as an argument.
+func SetupRouter(store database.GameStore) *gin.Engine {
 	router := gin.Default()
 
 	// Add CORS middleware
@@ -49,58 +52,308 @@ func SetupRouter(gameCollection *mongo.Collection) *gin.Engine {
 
 	// GetGameByID godoc
 	// @Summary Get a single game report by its ID
-	// @Description Retrieves full details for a specific game based on its unique ID.
+	// @Description Retrieves full details for a specific game based on its unique ID. Awards are recomputed from the kill log when multikill_window is given; otherwise they use the window the game was uploaded with.
 	// @Tags games
 	// @Accept json
 	// @Produce json
-	// @Param id path int true "Game ID"
+	// @Param id path string true "Game ID"
+	// @Param multikill_window query string false "Longest gap between two kills of a multi-kill, as a Go duration (default: the upload's window)"
 	// @Success 200 {object} reporter.GameReport "Successfully retrieved game report"
-	// @Failure 400 {object} ErrorResponse "Invalid game ID format"
+	// @Failure 400 {object} ErrorResponse "Invalid game ID format or multikill_window"
 	// @Failure 404 {object} ErrorResponse "Game not found"
 	// @Failure 500 {object} ErrorResponse "Failed to retrieve game data"
 	// @Router /games/{id} [get]
 	router.GET("/games/:id", func(c *gin.Context) {
-		gameIDStr := c.Param("id")
-		gameID, err := strconv.Atoi(gameIDStr)
-		if err != nil {
+		gameID := c.Param("id")
+		if !reporter.ValidGameID(gameID) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid game ID format"})
 			return
 		}
+		window, err := multiKillWindowFromQuery(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 
 		// Create a new context for this specific request
 		reqCtx, reqCancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer reqCancel()
 
-		report, err := database.GetGameReportByID(reqCtx, gameCollection, gameID)
+		report, err := store.GetGameReport(reqCtx, gameID)
 		if err != nil {
-			log.Printf("Error retrieving game ID %d from database: %v", gameID, err)
+			log.Printf("Error retrieving game ID %s from database: %v", gameID, err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve game data"})
 			return
 		}
 
 		if report == nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Game with ID %d not found", gameID)})
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Game with ID %s not found", gameID)})
 			return
 		}
+		if window > 0 {
+			report.Awards, report.MultiKillWindow = reporter.ComputeAwards(report.KillLog, window), window.String()
+		}
 
 		c.JSON(http.StatusOK, report)
 	})
 
+	// GetGameDiagnostics godoc
+	// @Summary Get the parse diagnostics of a game
+	// @Description Lists the anomalies (unterminated game, unknown client, unparseable line, ...) found while parsing a game, in log order.
+	// @Tags games
+	// @Accept json
+	// @Produce json
+	// @Param id path string true "Game ID"
+	// @Param severity query string false "Only diagnostics of this severity (warning, error)"
+	// @Success 200 {array} reporter.Diagnostic "Successfully retrieved game diagnostics"
+	// @Failure 400 {object} ErrorResponse "Invalid game ID format"
+	// @Failure 404 {object} ErrorResponse "Game not found"
+	// @Failure 500 {object} ErrorResponse "Failed to retrieve game data"
+	// @Router /games/{id}/diagnostics [get]
+	router.GET("/games/:id/diagnostics", func(c *gin.Context) {
+		gameID := c.Param("id")
+		if !reporter.ValidGameID(gameID) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid game ID format"})
+			return
+		}
+
+		reqCtx, reqCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer reqCancel()
+
+		report, err := store.GetGameReport(reqCtx, gameID)
+		if err != nil {
+			log.Printf("Error retrieving game ID %s from database: %v", gameID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve game data"})
+			return
+		}
+		if report == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Game with ID %s not found", gameID)})
+			return
+		}
+
+		severity := c.Query("severity")
+		diagnostics := make([]reporter.Diagnostic, 0, len(report.Diagnostics))
+		for _, d := range report.Diagnostics {
+			if severity == "" || d.Severity == severity {
+				diagnostics = append(diagnostics, d)
+			}
+		}
+
+		c.JSON(http.StatusOK, diagnostics)
+	})
+
+	// GetGameItems godoc
+	// @Summary Get the item pickups of a game
+	// @Description Counts the items picked up in a game by class (weapon, ammo, armor, health, powerup, holdable, flag) and by item, for the whole game and per player.
+	// @Tags games
+	// @Accept json
+	// @Produce json
+	// @Param id path string true "Game ID"
+	// @Success 200 {object} reporter.ItemsReport "Successfully retrieved item pickups"
+	// @Failure 400 {object} ErrorResponse "Invalid game ID format"
+	// @Failure 404 {object} ErrorResponse "Game not found"
+	// @Failure 500 {object} ErrorResponse "Failed to retrieve game data"
+	// @Router /games/{id}/items [get]
+	router.GET("/games/:id/items", func(c *gin.Context) {
+		gameID := c.Param("id")
+		if !reporter.ValidGameID(gameID) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid game ID format"})
+			return
+		}
+
+		reqCtx, reqCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer reqCancel()
+
+		report, err := store.GetGameReport(reqCtx, gameID)
+		if err != nil {
+			log.Printf("Error retrieving game ID %s from database: %v", gameID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve game data"})
+			return
+		}
+		if report == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Game with ID %s not found", gameID)})
+			return
+		}
+
+		items := report.Items
+		if items == nil {
+			// A game without pickups still gets a well-formed, empty report
+			items = &reporter.ItemsReport{
+				ItemCounts: reporter.ItemCounts{ByClass: map[string]int{}, ByItem: map[string]int{}},
+				Players:    map[string]reporter.ItemCounts{},
+			}
+		}
+
+		c.JSON(http.StatusOK, items)
+	})
+
+	// GetGameChat godoc
+	// @Summary Get the chat log of a game
+	// @Description Lists the say lines of a game in log order, with timestamp, client ID and the resolved player name.
+	// @Tags games
+	// @Accept json
+	// @Produce json
+	// @Param id path string true "Game ID"
+	// @Param q query string false "Only messages whose text contains this string (case-insensitive)"
+	// @Param player query string false "Only messages sent by this player (final or in-message name)"
+	// @Success 200 {array} reporter.ChatMessage "Successfully retrieved chat messages"
+	// @Failure 400 {object} ErrorResponse "Invalid game ID format"
+	// @Failure 404 {object} ErrorResponse "Game not found"
+	// @Failure 500 {object} ErrorResponse "Failed to retrieve game data"
+	// @Router /games/{id}/chat [get]
+	router.GET("/games/:id/chat", func(c *gin.Context) {
+		gameID := c.Param("id")
+		if !reporter.ValidGameID(gameID) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid game ID format"})
+			return
+		}
+
+		reqCtx, reqCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer reqCancel()
+
+		report, err := store.GetGameReport(reqCtx, gameID)
+		if err != nil {
+			log.Printf("Error retrieving game ID %s from database: %v", gameID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve game data"})
+			return
+		}
+		if report == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Game with ID %s not found", gameID)})
+			return
+		}
+
+		query := strings.ToLower(c.Query("q"))
+		player := c.Query("player")
+		chat := make([]reporter.ChatMessage, 0, len(report.Chat))
+		for _, msg := range report.Chat {
+			if query != "" && !strings.Contains(strings.ToLower(msg.Text), query) {
+				continue
+			}
+			if player != "" && msg.Player != player && msg.Name != player {
+				continue
+			}
+			chat = append(chat, msg)
+		}
+
+		c.JSON(http.StatusOK, chat)
+	})
+
+	// GetGameKillMatrix godoc
+	// @Summary Get the who-killed-whom matrix of a game
+	// @Description Returns how many times each player killed each other player, with every player's favourite victim and nemesis (the player who killed them most).
+	// @Tags games
+	// @Accept json
+	// @Produce json
+	// @Param id path string true "Game ID"
+	// @Success 200 {object} reporter.KillMatrixReport "Successfully retrieved kill matrix"
+	// @Failure 400 {object} ErrorResponse "Invalid game ID format"
+	// @Failure 404 {object} ErrorResponse "Game not found"
+	// @Failure 500 {object} ErrorResponse "Failed to retrieve game data"
+	// @Router /games/{id}/matrix [get]
+	router.GET("/games/:id/matrix", func(c *gin.Context) {
+		gameID := c.Param("id")
+		if !reporter.ValidGameID(gameID) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid game ID format"})
+			return
+		}
+
+		reqCtx, reqCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer reqCancel()
+
+		matrix, err := store.GetKillMatrix(reqCtx, gameID)
+		if err != nil {
+			log.Printf("Error retrieving kill matrix of game ID %s from database: %v", gameID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve game data"})
+			return
+		}
+		if matrix == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Game with ID %s not found", gameID)})
+			return
+		}
+
+		c.JSON(http.StatusOK, reporter.KillMatrixReport{Matrix: matrix, Rivalries: reporter.Rivalries(matrix)})
+	})
+
+	// GetGameTimeline godoc
+	// @Summary Get the kill timeline of a game
+	// @Description Groups a game's kills into time buckets, with kills by player and by means in each bucket and every player's cumulative net score at the end of each bucket.
+	// @Tags games
+	// @Accept json
+	// @Produce json
+	// @Param id path string true "Game ID"
+	// @Param bucket query string false "Bucket size as a Go duration in whole seconds (default 30s)"
+	// @Success 200 {object} reporter.Timeline "Successfully built the timeline"
+	// @Failure 400 {object} ErrorResponse "Invalid game ID format or bucket size"
+	// @Failure 404 {object} ErrorResponse "Game not found"
+	// @Failure 500 {object} ErrorResponse "Failed to retrieve game data"
+	// @Router /games/{id}/timeline [get]
+	router.GET("/games/:id/timeline", func(c *gin.Context) {
+		gameID := c.Param("id")
+		if !reporter.ValidGameID(gameID) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid game ID format"})
+			return
+		}
+
+		bucket := 30 * time.Second
+		if bucketStr := c.Query("bucket"); bucketStr != "" {
+			parsed, err := time.ParseDuration(bucketStr)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid bucket value %q", bucketStr)})
+				return
+			}
+			bucket = parsed
+		}
+
+		reqCtx, reqCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer reqCancel()
+
+		report, err := store.GetGameReport(reqCtx, gameID)
+		if err != nil {
+			log.Printf("Error retrieving game ID %s from database: %v", gameID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve game data"})
+			return
+		}
+		if report == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Game with ID %s not found", gameID)})
+			return
+		}
+
+		timeline, err := reporter.BuildTimeline(report.KillLog, report.DurationSeconds, bucket)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid bucket: %v", err)})
+			return
+		}
+
+		c.JSON(http.StatusOK, timeline)
+	})
+
 	// GetAllGames godoc
 	// @Summary Get all game reports
-	// @Description Retrieves a list of all game reports stored in the database, sorted by game ID.
+	// @Description Retrieves a list of all game reports stored in the database, in the order they were stored (by upload time, then source and position in the log). Optional query parameters filter on how the game ended.
 	// @Tags games
 	// @Accept json
 	// @Produce json
+	// @Param exit_reason query string false "Case-insensitive part of the exit reason (e.g. fraglimit, timelimit, capturelimit)"
+	// @Param aborted query bool false "Only games that ended without (true) or with (false) an Exit line"
+	// @Param min_duration query int false "Minimum game duration in seconds"
+	// @Param max_duration query int false "Maximum game duration in seconds"
+	// @Param upload_id query string false "Only games first stored by this upload"
 	// @Success 200 {array} reporter.GameReport "Successfully retrieved list of game reports"
+	// @Failure 400 {object} ErrorResponse "Invalid filter parameter"
 	// @Failure 500 {object} ErrorResponse "Failed to retrieve game reports"
 	// @Router /games [get]
 	router.GET("/games", func(c *gin.Context) {
+		filter, err := gameFilterFromQuery(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
 		// Create a new context for this specific request
 		reqCtx, reqCancel := context.WithTimeout(context.Background(), 15*time.Second) // Slightly longer timeout for potentially larger data
 		defer reqCancel()
 
-		reports, err := database.GetAllGameReports(reqCtx, gameCollection)
+		reports, err := store.FindGameReports(reqCtx, filter)
 		if err != nil {
 			log.Printf("Error retrieving all game reports from database: %v", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve game reports"})
@@ -116,16 +369,47 @@ func SetupRouter(gameCollection *mongo.Collection) *gin.Engine {
 	// UploadLogFile godoc
 	// @Summary Upload a Quake log file for processing
 	// @Description Uploads a game log file (.log). The server parses it, generates game reports, and stores them.
+	// @Description Each game's ID is a hash of the log source, its position in the log and its lines, so uploading the same log again stores nothing new.
+	// @Description Every upload is recorded (see /uploads) and owns the games it stored first.
+	// @Description A file with the same SHA-256 and source as an earlier upload is not processed again while its games are still stored: they are reported as skipped, unless force is set.
+	// @Description Otherwise each game is new, or a duplicate of a stored game (or of an earlier game in the file) with the same lines, whatever its source.
 	// @Tags games
 	// @Accept multipart/form-data
 	// @Produce json
 	// @Param logFile formData file true "The Quake log file to upload"
-	// @Success 201 {object} UploadResponse "Log file processed and game(s) stored successfully"
-	// @Success 200 {object} UploadResponse "Log file processed. No games found to report (already OK if file is valid but empty of games)"
+	// @Param mode query string false "Parse mode: lenient (default) keeps going and reports diagnostics, strict rejects the file on its first anomaly" Enums(lenient, strict)
+	// @Param multikill_window query string false "Longest gap between two kills of a multi-kill, as a Go duration (default 3s)"
+	// @Param source query string false "Name of the log the games come from, e.g. a server name, so a growing log keeps its game IDs (default: the SHA-256 of the file)"
+	// @Param uploader query string false "Who is uploading the file, recorded with the upload"
+	// @Param force query bool false "Check the games of a file uploaded before instead of skipping them"
+	// @Success 201 {object} UploadResponse "Log file processed and new game(s) stored successfully, with what happened to each game and any parse diagnostics"
+	// @Success 200 {object} UploadResponse "Log file processed, nothing new stored: no games, only duplicates, or a file uploaded before"
 	// @Failure 400 {object} ErrorResponse "Error retrieving/parsing uploaded file or invalid file format"
+	// @Failure 422 {object} StrictModeErrorResponse "Strict mode: the log contains an anomaly"
 	// @Failure 500 {object} ErrorResponse "Server error during file processing or storage"
 	// @Router /games/upload [post]
 	router.POST("/games/upload", func(c *gin.Context) {
+		parseMode, err := parser.ParseMode(c.Query("mode"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid mode: %v", err)})
+			return
+		}
+
+		force := false
+		if forceStr := c.Query("force"); forceStr != "" {
+			if force, err = strconv.ParseBool(forceStr); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid force value %q", forceStr)})
+				return
+			}
+		}
+
+		window, err := multiKillWindowFromQuery(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		reportOpts := reporter.Options{MultiKillWindow: window}
+
 		// Source
 		fileHeader, err := c.FormFile("logFile")
 		if err != nil {
@@ -140,70 +424,157 @@ func SetupRouter(gameCollection *mongo.Collection) *gin.Engine {
 		}
 		defer uploadedFile.Close()
 
-		// Create a temporary file
-		// The first argument "" means use the default directory for temporary files.
-		// The second argument "upload-*.log" is a pattern for the temporary file name.
-		tempFile, err := os.CreateTemp("", "upload-*.log")
+		// --- Parse the Log File ---
+		// The upload is parsed straight from the multipart stream, no temporary file needed.
+		// Use a new context for potentially long-running operations
+		procCtx, procCancel := context.WithTimeout(c.Request.Context(), 60*time.Second) // e.g., 1 minute timeout for processing
+		defer procCancel()
+
+		fileHash := sha256.New()
+		parseResult, err := parser.Parse(procCtx, io.TeeReader(uploadedFile, fileHash), parser.Options{Mode: parseMode})
+		var strictErr *parser.ParseError
+		if errors.As(err, &strictErr) {
+			// Strict mode rejects the whole file on its first anomaly
+			c.JSON(http.StatusUnprocessableEntity, gin.H{
+				"error":      fmt.Sprintf("Log file rejected in strict mode at line %d: %s", strictErr.Line, strictErr.Message),
+				"diagnostic": reporter.FormatDiagnostics([]parser.Diagnostic{strictErr.Diagnostic})[0],
+			})
+			return
+		}
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Error creating temporary file: %v", err)})
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Error parsing log file: %v", err)})
 			return
 		}
-		// Crucial: Ensure the temporary file is closed and removed.
-		// Close it first, then remove. Defer runs in LIFO order.
-		defer os.Remove(tempFile.Name()) // Remove the file
-		defer tempFile.Close()          // Close the file
+		parsedGames := parseResult.Games
+		diagnostics := reporter.FormatDiagnostics(parseResult.Diagnostics)
 
-		// Copy uploaded file content to the temporary file
-		_, err = io.Copy(tempFile, uploadedFile)
+		uploadID, err := database.NewUploadID()
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Error copying to temporary file: %v", err)})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Error recording upload: %v", err)})
 			return
 		}
 
-		// Ensure all content is written to disk before parsing
-		if err := tempFile.Sync(); err != nil {
-		    log.Printf("Warning: failed to sync temporary file %s: %v", tempFile.Name(), err)
-		    // Depending on the OS, this might not be strictly necessary after Close(),
-		    // but explicit sync can be safer before another process (parser) reads it.
+		// --- Format the Report ---
+		fileSHA256 := hex.EncodeToString(fileHash.Sum(nil))
+		reportOpts.Source = c.Query("source")
+		if reportOpts.Source == "" {
+			reportOpts.Source = fileSHA256
+		}
+		reportsToStore := reporter.FormatGameDataWithOptions(parsedGames, reportOpts)
+
+		// Games are stored in log order, all with the same upload time
+		uploadedAt := time.Now().UTC()
+		reports := make([]reporter.GameReport, 0, len(reportsToStore))
+		for _, report := range reportsToStore {
+			report.UploadedAt = uploadedAt
+			report.UploadID = uploadID
+			reports = append(reports, report)
+		}
+		sort.Slice(reports, func(i, j int) bool { return reports[i].Ordinal < reports[j].Ordinal })
+		gameIDs := make([]string, len(reports))
+		for i, report := range reports {
+			gameIDs[i] = report.ID
 		}
 
-		tempFilePath := tempFile.Name()
-
-		// --- Parse the Log File ---
-		// Use a new context for potentially long-running operations
-		procCtx, procCancel := context.WithTimeout(c.Request.Context(), 60*time.Second) // e.g., 1 minute timeout for processing
-		defer procCancel()
-
-		parsedGames, err := parser.ParseLogFile(tempFilePath)
+		// --- Record the Upload ---
+		// Looked up before recording this upload, which has the same SHA-256
+		previousUploads, err := store.FindUploadsBySHA256(procCtx, fileSHA256)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Error parsing log file: %v", err)})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Error looking up earlier uploads: %v", err)})
 			return
 		}
+		// Another source gives the same lines other game IDs, so only an upload from the same source is the same import
+		duplicateOfUpload := ""
+		for _, previous := range previousUploads {
+			if previous.Source == reportOpts.Source {
+				duplicateOfUpload = previous.ID // The latest
+				break
+			}
+		}
 
-		if len(parsedGames) == 0 {
-			c.JSON(http.StatusOK, gin.H{"message": "Log file processed. No games found to report.", "games_processed": 0})
+		// Every parsed file is recorded, even one without games or seen before, so imports can be audited
+		upload := database.Upload{
+			ID:            uploadID,
+			Filename:      fileHeader.Filename,
+			Size:          fileHeader.Size,
+			SHA256:        fileSHA256,
+			Source:        reportOpts.Source,
+			Uploader:      c.Query("uploader"),
+			ClientIP:      c.ClientIP(),
+			UploadedAt:    uploadedAt,
+			ParserVersion: parser.Version,
+			ParseMode:     string(parseMode),
+			GameIDs:       gameIDs,
+		}
+		if err := store.StoreUpload(procCtx, upload); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Error recording upload: %v", err)})
 			return
 		}
 
-		// --- Format the Report ---
-		reportsToStore := reporter.FormatGameData(parsedGames)
+		if len(reports) == 0 {
+			c.JSON(http.StatusOK, gin.H{"message": "Log file processed. No games found to report.", "games_processed": 0, "upload_id": uploadID, "diagnostics": diagnostics})
+			return
+		}
 
 		// --- Store in Database ---
-		reportsForDB := make(map[int]interface{}, len(reportsToStore))
-		for k, v := range reportsToStore {
-			reportsForDB[k] = v
+		var outcomes []database.GameOutcome
+		var message string
+		if duplicateOfUpload != "" && !force {
+			// The same file was ingested before: do not reprocess its games, unless some of them were deleted since
+			outcomes, err = database.SkipStoredGameReports(procCtx, store, reports)
+			if err != nil {
+				if _, _, delErr := store.DeleteUpload(procCtx, uploadID); delErr != nil {
+					log.Printf("Error rolling back upload %s: %v", uploadID, delErr)
+				}
+				c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Error looking up stored games: %v", err)})
+				return
+			}
+			if outcomes != nil {
+				message = fmt.Sprintf("Log file already uploaded as upload %s, %d game(s) skipped. Set force=true to check its games again.", duplicateOfUpload, len(reports))
+			}
+		}
+		if outcomes == nil {
+			outcomes, err = database.StoreNewGameReports(procCtx, store, reports)
+			if err != nil {
+				// Roll back whatever part of the upload made it in
+				if _, _, delErr := store.DeleteUpload(procCtx, uploadID); delErr != nil {
+					log.Printf("Error rolling back upload %s: %v", uploadID, delErr)
+				}
+				c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Error storing game reports: %v", err)})
+				return
+			}
 		}
 
-		err = database.StoreGameReports(procCtx, gameCollection, reportsForDB)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Error storing game reports: %v", err)})
-			return
+		counts := make(map[string]int)
+		for _, outcome := range outcomes {
+			counts[outcome.Status]++
+		}
+		if message == "" {
+			message = fmt.Sprintf("Log file processed: %d new game(s) stored, %d duplicate(s) ignored.", counts[database.GameNew], counts[database.GameDuplicate])
 		}
 
-		c.JSON(http.StatusCreated, gin.H{
-			"message":         fmt.Sprintf("Log file processed and %d game(s) stored successfully.", len(reportsToStore)),
-			"games_processed": len(reportsToStore),
-		})
+		status := http.StatusOK
+		if counts[database.GameNew] > 0 {
+			status = http.StatusCreated
+			refreshRatings(procCtx, store)
+		}
+
+		response := gin.H{
+			"message":         message,
+			"upload_id":       uploadID,
+			"games_processed": len(reports),
+			"games_stored":    counts[database.GameNew],
+			"games_duplicate": counts[database.GameDuplicate],
+			"games_skipped":   counts[database.GameSkipped],
+			"source":          reportOpts.Source,
+			"game_ids":        gameIDs,
+			"games":           outcomes,
+			"diagnostics":     diagnostics,
+		}
+		if duplicateOfUpload != "" {
+			response["duplicate_of_upload"] = duplicateOfUpload
+		}
+		c.JSON(status, response)
 	})
 
 	// DeleteAllGames godoc
@@ -220,12 +591,13 @@ func SetupRouter(gameCollection *mongo.Collection) *gin.Engine {
 		dbCtx, dbCancel := context.WithTimeout(c.Request.Context(), 30*time.Second) // Context for the database call
 		defer dbCancel()
 
-		err := database.DeleteAllGameReportsFromDB(dbCtx, gameCollection)
+		err := store.DeleteAllGameReports(dbCtx)
 		if err != nil {
 			log.Printf("Error deleting all game reports from database: %v", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete all game reports"})
 			return
 		}
+		refreshRatings(dbCtx, store)
 
 		c.JSON(http.StatusOK, gin.H{"message": "All game reports deleted successfully"})
 		// Alternatively, could use http.StatusNoContent and send no body:
@@ -238,16 +610,15 @@ func SetupRouter(gameCollection *mongo.Collection) *gin.Engine {
 	// @Tags games
 	// @Accept json
 	// @Produce json
-	// @Param id path int true "Game ID"
+	// @Param id path string true "Game ID"
 	// @Success 200 {object} SuccessResponse "Game deleted successfully"
 	// @Failure 400 {object} ErrorResponse "Invalid game ID format"
 	// @Failure 404 {object} ErrorResponse "Game not found"
 	// @Failure 500 {object} ErrorResponse "Failed to delete game report"
 	// @Router /games/{id} [delete]
 	router.DELETE("/games/:id", func(c *gin.Context) {
-		gameIDStr := c.Param("id")
-		gameID, err := strconv.Atoi(gameIDStr)
-		if err != nil {
+		gameID := c.Param("id")
+		if !reporter.ValidGameID(gameID) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid game ID format"})
 			return
 		}
@@ -256,62 +627,335 @@ func SetupRouter(gameCollection *mongo.Collection) *gin.Engine {
 		dbCtx, dbCancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
 		defer dbCancel()
 
-		deleted, err := database.DeleteGameReportByID(dbCtx, gameCollection, gameID)
+		deleted, err := store.DeleteGameReport(dbCtx, gameID)
 		if err != nil {
-			log.Printf("Error deleting game ID %d from database: %v", gameID, err)
+			log.Printf("Error deleting game ID %s from database: %v", gameID, err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete game report"})
 			return
 		}
-		if deleted == 0 {
-			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Game with ID %d not found", gameID)})
+		if !deleted {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Game with ID %s not found", gameID)})
 			return
 		}
+		refreshRatings(dbCtx, store)
 
-		c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("Game with ID %d deleted successfully", gameID)})
+		c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("Game with ID %s deleted successfully", gameID)})
 	})
 
 	// GetPlayersRanking godoc
 	// @Summary Get aggregated player rankings across all games
-	// @Description Retrieves a list of players ranked by their total kills across all recorded games.
+	// @Description Ranks players across all recorded games by the selected metric. Ties are broken by net score, kills, fewest deaths, most games and then name, so the order is stable; players with the same metric value share a rank.
 	// @Tags rankings
 	// @Accept json
 	// @Produce json
+	// @Param metric query string false "Ranking metric: score (net kills, default), kills, kd, kpm (kills per minute) or wins" Enums(score, kills, kd, kpm, wins)
+	// @Param min_games query int false "Only rank players with at least this many games"
 	// @Success 200 {array} reporter.PlayerRankEntry "Successfully retrieved player rankings"
+	// @Failure 400 {object} ErrorResponse "Invalid metric or min_games"
 	// @Failure 500 {object} ErrorResponse "Failed to retrieve player rankings"
 	// @Router /playersranking [get]
 	router.GET("/playersranking", func(c *gin.Context) {
+		metric, err := reporter.ParseRankingMetric(c.Query("metric"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid metric: %v", err)})
+			return
+		}
+		minGames := 0
+		if minGamesStr := c.Query("min_games"); minGamesStr != "" {
+			minGames, err = strconv.Atoi(minGamesStr)
+			if err != nil || minGames < 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid min_games value %q", minGamesStr)})
+				return
+			}
+		}
+
 		// Create a new context for this specific request
 		reqCtx, reqCancel := context.WithTimeout(context.Background(), 30*time.Second) // Longer timeout for aggregation
 		defer reqCancel()
 
-		allReports, err := database.GetAllGameReports(reqCtx, gameCollection)
+		playerRanks, err := store.RankPlayers(reqCtx, reporter.RankingOptions{Metric: metric, MinGames: minGames})
 		if err != nil {
 			log.Printf("Error retrieving all game reports for ranking: %v", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve data for player rankings"})
 			return
 		}
 
-		aggregatedKills := make(map[string]int)
-		for _, report := range allReports {
-			// The report.Kills map is player -> kills in that specific game
-			for player, killsInGame := range report.Kills {
-				aggregatedKills[player] += killsInGame
+		c.JSON(http.StatusOK, playerRanks)
+	})
+
+	// GetPlayerWeapons godoc
+	// @Summary Get a player's kills and deaths by weapon
+	// @Description Sums a player's kills and deaths by means of death over every stored game, with the weapon they killed most with and the one they died to most.
+	// @Tags players
+	// @Accept json
+	// @Produce json
+	// @Param name path string true "Player name, as they ended each game"
+	// @Success 200 {object} reporter.PlayerWeapons "Successfully retrieved weapon breakdown"
+	// @Failure 404 {object} ErrorResponse "Player not found in any game"
+	// @Failure 500 {object} ErrorResponse "Failed to retrieve data for the weapon breakdown"
+	// @Router /players/{name}/weapons [get]
+	router.GET("/players/:name/weapons", func(c *gin.Context) {
+		name := c.Param("name")
+
+		reqCtx, reqCancel := context.WithTimeout(context.Background(), 30*time.Second) // Longer timeout for aggregation
+		defer reqCancel()
+
+		allReports, err := store.FindGameReports(reqCtx, database.GameFilter{})
+		if err != nil {
+			log.Printf("Error retrieving all game reports for the weapons of %s: %v", name, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve data for the weapon breakdown"})
+			return
+		}
+
+		weapons := reporter.AggregateWeapons(name, allReports)
+		if weapons.Games == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Player %s not found in any game", name)})
+			return
+		}
+
+		c.JSON(http.StatusOK, weapons)
+	})
+
+	// GetRatings godoc
+	// @Summary Get the skill ratings of all players
+	// @Description Lists every player's Elo rating, best first. Ratings are recomputed by replaying all stored games in the order they were stored whenever games are uploaded or deleted; each game counts final placement by net score and every kill of an opponent as a duel (suicides and teamkills are not duels).
+	// @Tags ratings
+	// @Accept json
+	// @Produce json
+	// @Param min_games query int false "Only players with at least this many rated games"
+	// @Success 200 {array} rating.PlayerRating "Successfully retrieved ratings (history omitted)"
+	// @Failure 400 {object} ErrorResponse "Invalid min_games"
+	// @Failure 500 {object} ErrorResponse "Failed to retrieve ratings"
+	// @Router /ratings [get]
+	router.GET("/ratings", func(c *gin.Context) {
+		minGames := 0
+		if minGamesStr := c.Query("min_games"); minGamesStr != "" {
+			var err error
+			minGames, err = strconv.Atoi(minGamesStr)
+			if err != nil || minGames < 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid min_games value %q", minGamesStr)})
+				return
 			}
 		}
 
-		// Convert map to slice of PlayerRankEntry for sorting and JSON response
-		var playerRanks []reporter.PlayerRankEntry
-		for player, totalKills := range aggregatedKills {
-			playerRanks = append(playerRanks, reporter.PlayerRankEntry{PlayerName: player, TotalKills: totalKills})
+		reqCtx, reqCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer reqCancel()
+
+		ratings, err := store.GetRatings(reqCtx)
+		if err != nil {
+			log.Printf("Error retrieving ratings: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve ratings"})
+			return
 		}
 
-		// Sort players by total kills in descending order
-		sort.Slice(playerRanks, func(i, j int) bool {
-			return playerRanks[i].TotalKills > playerRanks[j].TotalKills
-		})
+		filtered := make([]rating.PlayerRating, 0, len(ratings))
+		for _, r := range ratings {
+			if r.Games >= minGames {
+				r.History = nil // The full history is served by /players/{name}/rating-history
+				filtered = append(filtered, r)
+			}
+		}
 
-		c.JSON(http.StatusOK, playerRanks)
+		c.JSON(http.StatusOK, filtered)
+	})
+
+	// GetPlayerRatingHistory godoc
+	// @Summary Get a player's rating history
+	// @Description Returns a player's current Elo rating with their rating after every rated game, oldest first.
+	// @Tags ratings
+	// @Accept json
+	// @Produce json
+	// @Param name path string true "Player name"
+	// @Success 200 {object} rating.PlayerRating "Successfully retrieved rating history"
+	// @Failure 404 {object} ErrorResponse "Player has no rating"
+	// @Failure 500 {object} ErrorResponse "Failed to retrieve rating history"
+	// @Router /players/{name}/rating-history [get]
+	router.GET("/players/:name/rating-history", func(c *gin.Context) {
+		name := c.Param("name")
+
+		reqCtx, reqCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer reqCancel()
+
+		playerRating, err := store.GetRating(reqCtx, name)
+		if err != nil {
+			log.Printf("Error retrieving rating history of %s: %v", name, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve rating history"})
+			return
+		}
+		if playerRating == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Player %s has no rating", name)})
+			return
+		}
+
+		c.JSON(http.StatusOK, playerRating)
+	})
+
+	// ListUploads godoc
+	// @Summary List uploaded log files
+	// @Description Returns every recorded upload, newest first: file name, size and SHA-256, who uploaded it, the parser version and the games in it.
+	// @Tags uploads
+	// @Accept json
+	// @Produce json
+	// @Success 200 {array} database.Upload "Successfully retrieved uploads"
+	// @Failure 500 {object} ErrorResponse "Failed to retrieve uploads"
+	// @Router /uploads [get]
+	router.GET("/uploads", func(c *gin.Context) {
+		reqCtx, reqCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer reqCancel()
+
+		uploads, err := store.ListUploads(reqCtx)
+		if err != nil {
+			log.Printf("Error retrieving uploads: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve uploads"})
+			return
+		}
+
+		c.JSON(http.StatusOK, uploads)
+	})
+
+	// GetUploadByID godoc
+	// @Summary Get a single upload by its ID
+	// @Description Returns an upload with the IDs of the games it stored first, which are the games deleting it removes.
+	// @Tags uploads
+	// @Accept json
+	// @Produce json
+	// @Param id path string true "Upload ID"
+	// @Success 200 {object} UploadDetails "Successfully retrieved upload"
+	// @Failure 400 {object} ErrorResponse "Invalid upload ID format"
+	// @Failure 404 {object} ErrorResponse "Upload not found"
+	// @Failure 500 {object} ErrorResponse "Failed to retrieve upload"
+	// @Router /uploads/{id} [get]
+	router.GET("/uploads/:id", func(c *gin.Context) {
+		uploadID := c.Param("id")
+		if !database.ValidUploadID(uploadID) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid upload ID format"})
+			return
+		}
+
+		reqCtx, reqCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer reqCancel()
+
+		upload, err := store.GetUpload(reqCtx, uploadID)
+		if err != nil {
+			log.Printf("Error retrieving upload %s: %v", uploadID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve upload"})
+			return
+		}
+		if upload == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Upload with ID %s not found", uploadID)})
+			return
+		}
+
+		stored, err := store.FindGameReports(reqCtx, database.GameFilter{UploadID: uploadID})
+		if err != nil {
+			log.Printf("Error retrieving games of upload %s: %v", uploadID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve upload"})
+			return
+		}
+		details := UploadDetails{Upload: *upload, StoredGameIDs: make([]string, len(stored))}
+		for i, report := range stored {
+			details.StoredGameIDs[i] = report.ID
+		}
+
+		c.JSON(http.StatusOK, details)
+	})
+
+	// DeleteUploadByID godoc
+	// @Summary Roll back an upload
+	// @Description Deletes an upload together with the games it stored first. Games it only found already stored are kept.
+	// @Tags uploads
+	// @Accept json
+	// @Produce json
+	// @Param id path string true "Upload ID"
+	// @Success 200 {object} DeleteUploadResponse "Upload and its games deleted successfully"
+	// @Failure 400 {object} ErrorResponse "Invalid upload ID format"
+	// @Failure 404 {object} ErrorResponse "Upload not found"
+	// @Failure 500 {object} ErrorResponse "Failed to delete upload"
+	// @Router /uploads/{id} [delete]
+	router.DELETE("/uploads/:id", func(c *gin.Context) {
+		uploadID := c.Param("id")
+		if !database.ValidUploadID(uploadID) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid upload ID format"})
+			return
+		}
+
+		dbCtx, dbCancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+		defer dbCancel()
+
+		found, gamesDeleted, err := store.DeleteUpload(dbCtx, uploadID)
+		if err != nil {
+			log.Printf("Error deleting upload %s: %v", uploadID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete upload"})
+			return
+		}
+		if !found {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Upload with ID %s not found", uploadID)})
+			return
+		}
+		if gamesDeleted > 0 {
+			refreshRatings(dbCtx, store)
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":       fmt.Sprintf("Upload with ID %s and %d game(s) deleted successfully", uploadID, gamesDeleted),
+			"games_deleted": gamesDeleted,
+		})
 	})
 
 	return router
-} 
\ No newline at end of file
+}
+
+// refreshRatings recomputes the stored player ratings after the stored games changed.
+// A failure is only logged: the games themselves were stored or deleted fine, and the next change retries.
+func refreshRatings(ctx context.Context, store database.GameStore) {
+	if err := database.RefreshRatings(ctx, store); err != nil {
+		log.Printf("Error refreshing player ratings: %v", err)
+	}
+}
+
+// gameFilterFromQuery builds a database.GameFilter from the query parameters of GET /games.
+func gameFilterFromQuery(c *gin.Context) (database.GameFilter, error) {
+	filter := database.GameFilter{ExitReason: c.Query("exit_reason")}
+
+	if abortedStr := c.Query("aborted"); abortedStr != "" {
+		aborted, err := strconv.ParseBool(abortedStr)
+		if err != nil {
+			return filter, fmt.Errorf("Invalid aborted value %q", abortedStr)
+		}
+		filter.Aborted = &aborted
+	}
+
+	for param, target := range map[string]*int{"min_duration": &filter.MinDuration, "max_duration": &filter.MaxDuration} {
+		valueStr := c.Query(param)
+		if valueStr == "" {
+			continue
+		}
+		value, err := strconv.Atoi(valueStr)
+		if err != nil || value < 0 {
+			return filter, fmt.Errorf("Invalid %s value %q", param, valueStr)
+		}
+		*target = value
+	}
+
+	if uploadID := c.Query("upload_id"); uploadID != "" {
+		if !database.ValidUploadID(uploadID) {
+			return filter, fmt.Errorf("Invalid upload_id value %q", uploadID)
+		}
+		filter.UploadID = uploadID
+	}
+
+	return filter, nil
+}
+
+// multiKillWindowFromQuery reads the multikill_window query parameter, 0 when it is not set.
+func multiKillWindowFromQuery(c *gin.Context) (time.Duration, error) {
+	windowStr := c.Query("multikill_window")
+	if windowStr == "" {
+		return 0, nil
+	}
+	window, err := time.ParseDuration(windowStr)
+	if err != nil || window <= 0 {
+		return 0, fmt.Errorf("Invalid multikill_window value %q", windowStr)
+	}
+	return window, nil
+}
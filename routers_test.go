@@ -1,117 +1,73 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 	"quake_log_parser/database" // Assuming database package is accessible
 	"quake_log_parser/reporter" // Assuming reporter package is accessible
 )
 
-const (
-	testMongoDBURI         = "mongodb://localhost:27017" // Or use an env variable
-	testDatabaseName       = "quake_test_db"
-	testGameReportsCollection = "test_game_reports"
-)
-
-var testMongoClient *mongo.Client
-var testGameCollection *mongo.Collection
+// uploadLog is a small log with one game, used to exercise POST /games/upload.
+const uploadLog = `  0:00 InitGame: \sv_hostname\Code Miner Server\g_gametype\0\fraglimit\20\timelimit\15\mapname\q3dm17
+  0:25 ClientConnect: 2
+  0:25 ClientUserinfoChanged: 2 n\Isgalamido\t\0\model\uriel/zael
+  0:27 ClientBegin: 2
+  0:29 ClientConnect: 3
+  0:29 ClientUserinfoChanged: 3 n\Dono da Bola\t\0\model\sarge
+  0:30 ClientBegin: 3
+  1:10 Kill: 2 3 7: Isgalamido killed Dono da Bola by MOD_ROCKET_SPLASH
+  1:41 Kill: 2 3 10: Isgalamido killed Dono da Bola by MOD_RAILGUN
+  1:55 Exit: Fraglimit hit.
+  2:00 ShutdownGame:
+`
 
-// TestMain sets up the MongoDB connection before tests run and closes it after.
+// TestMain runs the tests against in-memory stores, so no database is needed.
 func TestMain(m *testing.M) {
 	// Set Gin to test mode
 	gin.SetMode(gin.TestMode)
 
-	// Setup MongoDB connection
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	var err error
-	testMongoClient, err = database.ConnectDB(ctx, testMongoDBURI)
-	if err != nil {
-		log.Fatalf("Failed to connect to MongoDB for testing: %v", err)
-	}
-	defer func() {
-		if testMongoClient != nil {
-			disconnectCtx, cancelDisconnect := context.WithTimeout(context.Background(), 5*time.Second)
-			defer cancelDisconnect()
-			if err := testMongoClient.Disconnect(disconnectCtx); err != nil {
-				log.Printf("Failed to disconnect test MongoDB client: %v", err)
-			}
-		}
-	}()
-
-	testGameCollection = testMongoClient.Database(testDatabaseName).Collection(testGameReportsCollection)
-
-	// Run tests
-	exitVal := m.Run()
-
-	// Clean up the test database after all tests in the package are done (optional, but good practice)
-	// You might want to drop the entire collection or database if it's purely for testing.
-	cleanupCtx, cleanupCancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cleanupCancel()
-	err = testGameCollection.Drop(cleanupCtx)
-	if err != nil {
-		log.Printf("Warning: failed to drop test collection %s: %v", testGameCollection.Name(), err)
-	}
-
-
-	os.Exit(exitVal)
+	os.Exit(m.Run())
 }
 
 func TestGetGameByID_Success(t *testing.T) {
 	// 1. Prepare test data
-	gameID := 101
+	gameID := "101"
 	expectedReport := reporter.GameReport{
+		ID:           gameID,
 		TotalKills:   5,
 		Players:      []string{"Player1", "Player2"},
 		Kills:        map[string]int{"Player1": 3, "Player2": 2},
 		KillsByMeans: map[string]int{"MOD_RAILGUN": 5},
+		Map:          "q3dm17",
+		GameType:     0,
 	}
 
-	// 2. Insert test data into the test collection
-	// We need to wrap it in a document that includes the _id for MongoDB
-	docToInsert := bson.M{
-		"_id":              gameID, // This will be the game ID
-		"total_kills":    expectedReport.TotalKills,
-		"players":        expectedReport.Players,
-		"kills":          expectedReport.Kills,
-		"kills_by_means": expectedReport.KillsByMeans,
-	}
-
+	// 2. Store it
+	store := database.NewMemoryStore()
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	_, err := testGameCollection.InsertOne(ctx, docToInsert)
+	_, err := store.StoreGameReports(ctx, []reporter.GameReport{expectedReport})
 	if err != nil {
-		t.Fatalf("Failed to insert test game report: %v", err)
-	}
-	// Defer cleanup for this specific test item
-	defer func() {
-		cleanupCtx, cleanupCancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cleanupCancel()
-		_, delErr := testGameCollection.DeleteOne(cleanupCtx, bson.M{"_id": gameID})
-		if delErr != nil {
-			t.Logf("Warning: failed to delete test game report with ID %d: %v", gameID, delErr)
-		}
-	}()
+		t.Fatalf("Failed to store test game report: %v", err)
+	}
 
 	// 3. Setup router
-	router := SetupRouter(testGameCollection) // SetupRouter is from your routers.go
+	router := SetupRouter(store) // SetupRouter is from your routers.go
 
 	// 4. Create a new HTTP request
-	req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/games/%d", gameID), nil)
+	req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/games/%s", gameID), nil)
 	w := httptest.NewRecorder()
 
 	// 5. Serve HTTP
@@ -135,11 +91,14 @@ func TestGetGameByID_Success(t *testing.T) {
 	if len(actualReport.Players) != len(expectedReport.Players) {
 		t.Errorf("Expected %d players, got %d", len(expectedReport.Players), len(actualReport.Players))
 	}
+	if actualReport.Map != expectedReport.Map {
+		t.Errorf("Expected Map %q, got %q", expectedReport.Map, actualReport.Map)
+	}
 	// Add more detailed comparisons for maps and slices if necessary
 }
 
 func TestGetGameByID_NotFound(t *testing.T) {
-	router := SetupRouter(testGameCollection)
+	router := SetupRouter(database.NewMemoryStore())
 	nonExistentGameID := 99999
 
 	req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/games/%d", nonExistentGameID), nil)
@@ -162,7 +121,7 @@ func TestGetGameByID_NotFound(t *testing.T) {
 }
 
 func TestGetGameByID_InvalidIDFormat(t *testing.T) {
-	router := SetupRouter(testGameCollection)
+	router := SetupRouter(database.NewMemoryStore())
 
 	req, _ := http.NewRequest(http.MethodGet, "/games/not-an-id", nil)
 	w := httptest.NewRecorder()
@@ -180,4 +139,282 @@ func TestGetGameByID_InvalidIDFormat(t *testing.T) {
 	if errorResponse["error"] != expectedErrorMsg {
 		t.Errorf("Expected error message '%s', got '%s'", expectedErrorMsg, errorResponse["error"])
 	}
-} 
\ No newline at end of file
+}
+
+// newUploadRequest builds a POST /games/upload request carrying content as the logFile form field.
+func newUploadRequest(t *testing.T, content string, query string) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("logFile", "games.log")
+	if err != nil {
+		t.Fatalf("Failed to create form file: %v", err)
+	}
+	part.Write([]byte(content))
+	writer.Close()
+
+	req, _ := http.NewRequest(http.MethodPost, "/games/upload"+query, &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestUploadThenQuery(t *testing.T) {
+	router := SetupRouter(database.NewMemoryStore())
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, newUploadRequest(t, uploadLog, ""))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status code %d for the upload, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+	var upload UploadResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &upload); err != nil {
+		t.Fatalf("Failed to unmarshal upload response: %v", err)
+	}
+	if upload.GamesStored != 1 || len(upload.GameIDs) != 1 {
+		t.Fatalf("Expected 1 game to be stored, got %+v", upload)
+	}
+	gameID := upload.GameIDs[0]
+
+	// The game is listed
+	w = httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/games?exit_reason=fraglimit", nil)
+	router.ServeHTTP(w, req)
+	var reports []reporter.GameReport
+	if err := json.Unmarshal(w.Body.Bytes(), &reports); err != nil {
+		t.Fatalf("Failed to unmarshal games: %v", err)
+	}
+	if len(reports) != 1 || reports[0].TotalKills != 2 || reports[0].ID != gameID || reports[0].Ordinal != 1 {
+		t.Fatalf("Expected game %s with 2 kills, got %+v", gameID, reports)
+	}
+
+	// The ranking and ratings are computed from it
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/playersranking", nil)
+	router.ServeHTTP(w, req)
+	var ranking []reporter.PlayerRankEntry
+	if err := json.Unmarshal(w.Body.Bytes(), &ranking); err != nil {
+		t.Fatalf("Failed to unmarshal ranking: %v", err)
+	}
+	if len(ranking) != 2 || ranking[0].PlayerName != "Isgalamido" {
+		t.Errorf("Expected Isgalamido to lead a 2 player ranking, got %+v", ranking)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/players/Isgalamido/rating-history", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status code %d for the rating history, got %d", http.StatusOK, w.Code)
+	}
+
+	// Deleting every game empties the list
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodDelete, "/games", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d for the delete, got %d", http.StatusOK, w.Code)
+	}
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/games/"+gameID, nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status code %d after deleting every game, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+func TestGetGameByID_MultiKillWindow(t *testing.T) {
+	router := SetupRouter(database.NewMemoryStore())
+
+	// Isgalamido's two kills are 31s apart: a multi-kill only with the upload's 40s window
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, newUploadRequest(t, uploadLog, "?multikill_window=40s"))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status code %d for the upload, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+	var upload UploadResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &upload); err != nil {
+		t.Fatalf("Failed to unmarshal upload response: %v", err)
+	}
+
+	for query, expected := range map[string]struct {
+		window     string
+		multiKills int
+	}{"": {"40s", 1}, "?multikill_window=3s": {"3s", 0}, "?multikill_window=1m": {"1m0s", 1}} {
+		w = httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/games/"+upload.GameIDs[0]+query, nil)
+		router.ServeHTTP(w, req)
+		var report reporter.GameReport
+		if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+			t.Fatalf("Failed to unmarshal game: %v", err)
+		}
+		if report.MultiKillWindow != expected.window || report.Awards == nil || len(report.Awards.MultiKills) != expected.multiKills {
+			t.Errorf("GET %q: expected %d multi-kills with a %s window, got %+v with %q", query, expected.multiKills, expected.window, report.Awards, report.MultiKillWindow)
+		}
+	}
+
+	w = httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/games/"+upload.GameIDs[0]+"?multikill_window=soon", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d for an invalid window, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestUpload_Duplicates(t *testing.T) {
+	router := SetupRouter(database.NewMemoryStore())
+	upload := func(content, query string, wantStatus int) UploadResponse {
+		t.Helper()
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, newUploadRequest(t, content, query))
+		if w.Code != wantStatus {
+			t.Fatalf("Expected status code %d for the upload, got %d: %s", wantStatus, w.Code, w.Body.String())
+		}
+		var response UploadResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to unmarshal upload response: %v", err)
+		}
+		return response
+	}
+
+	first := upload(uploadLog, "", http.StatusCreated)
+	gameID := first.GameIDs[0]
+	if first.GamesStored != 1 || first.DuplicateOfUpload != "" || first.Games[0].Status != database.GameNew {
+		t.Fatalf("Expected game %s to be new, got %+v", gameID, first)
+	}
+
+	// The same file is skipped without looking at its games
+	again := upload(uploadLog, "", http.StatusOK)
+	if again.DuplicateOfUpload != first.UploadID || again.GamesSkipped != 1 || again.GamesStored != 0 ||
+		again.Games[0].Status != database.GameSkipped || again.Games[0].DuplicateOf != gameID || again.GameIDs[0] != gameID {
+		t.Errorf("Expected the same file to be skipped as a copy of upload %s, got %+v", first.UploadID, again)
+	}
+
+	// Forced, its game is found already stored under its own ID
+	forced := upload(uploadLog, "?force=true", http.StatusOK)
+	if forced.GamesDuplicate != 1 || forced.Games[0].Status != database.GameDuplicate || forced.Games[0].DuplicateOf != gameID {
+		t.Errorf("Expected game %s to be a duplicate of itself, got %+v", gameID, forced)
+	}
+
+	// The same lines from another source get another ID, but are still the same game
+	other := upload(uploadLog, "?source=server-2", http.StatusOK)
+	if other.GameIDs[0] == gameID || other.DuplicateOfUpload != "" ||
+		other.Games[0].Status != database.GameDuplicate || other.Games[0].DuplicateOf != gameID {
+		t.Errorf("Expected the game from server-2 to be a duplicate of %s, got %+v", gameID, other)
+	}
+
+	// A new file with a stored game and a new one
+	secondGame := strings.Replace(uploadLog, "q3dm17", "q3dm6", 1)
+	mixed := upload(uploadLog+secondGame+secondGame, "", http.StatusCreated)
+	if mixed.GamesStored != 1 || mixed.GamesDuplicate != 2 {
+		t.Fatalf("Expected 1 new game and 2 duplicates, got %+v", mixed)
+	}
+	wantStatuses := []string{database.GameDuplicate, database.GameNew, database.GameDuplicate}
+	wantDuplicateOf := []string{gameID, "", mixed.GameIDs[1]}
+	for i, outcome := range mixed.Games {
+		if outcome.Ordinal != i+1 || outcome.Status != wantStatuses[i] || outcome.DuplicateOf != wantDuplicateOf[i] {
+			t.Errorf("Expected game %d to be %s of %q, got %+v", i+1, wantStatuses[i], wantDuplicateOf[i], outcome)
+		}
+	}
+
+	upload(uploadLog, "?force=maybe", http.StatusBadRequest)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/games", nil)
+	router.ServeHTTP(w, req)
+	var reports []reporter.GameReport
+	json.Unmarshal(w.Body.Bytes(), &reports)
+	if len(reports) != 2 {
+		t.Errorf("Expected 2 stored games, got %d", len(reports))
+	}
+}
+
+func TestUpload_AfterDeletingGames(t *testing.T) {
+	router := SetupRouter(database.NewMemoryStore())
+	upload := func() UploadResponse {
+		t.Helper()
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, newUploadRequest(t, uploadLog, ""))
+		var response UploadResponse
+		json.Unmarshal(w.Body.Bytes(), &response)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status code %d for the upload, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+		}
+		return response
+	}
+
+	first := upload()
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodDelete, "/games", nil)
+	router.ServeHTTP(w, req)
+
+	// The earlier upload of the file is still recorded, but its game is gone, so it is stored again
+	again := upload()
+	if again.DuplicateOfUpload != first.UploadID || again.GamesStored != 1 || again.Games[0].Status != database.GameNew {
+		t.Errorf("Expected game %s to be stored again, got %+v", first.GameIDs[0], again)
+	}
+}
+
+func TestUpload_RollBack(t *testing.T) {
+	router := SetupRouter(database.NewMemoryStore())
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, newUploadRequest(t, uploadLog, "?uploader=ops"))
+	var upload UploadResponse
+	json.Unmarshal(w.Body.Bytes(), &upload)
+	if w.Code != http.StatusCreated || !database.ValidUploadID(upload.UploadID) {
+		t.Fatalf("Expected an upload ID, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/uploads/"+upload.UploadID, nil)
+	router.ServeHTTP(w, req)
+	var details UploadDetails
+	if err := json.Unmarshal(w.Body.Bytes(), &details); err != nil {
+		t.Fatalf("Failed to unmarshal upload: %v", err)
+	}
+	if details.Filename != "games.log" || details.Uploader != "ops" || details.Size != int64(len(uploadLog)) ||
+		len(details.StoredGameIDs) != 1 || details.StoredGameIDs[0] != upload.GameIDs[0] {
+		t.Fatalf("Expected the upload of games.log by ops with its game, got %+v", details)
+	}
+
+	// The second upload of the same file stores nothing, so rolling it back deletes nothing
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, newUploadRequest(t, uploadLog, ""))
+	var again UploadResponse
+	json.Unmarshal(w.Body.Bytes(), &again)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/uploads", nil)
+	router.ServeHTTP(w, req)
+	var uploads []database.Upload
+	json.Unmarshal(w.Body.Bytes(), &uploads)
+	if len(uploads) != 2 {
+		t.Fatalf("Expected 2 uploads, got %+v", uploads)
+	}
+
+	for _, tt := range []struct {
+		uploadID string
+		status   int
+		deleted  int
+	}{
+		{again.UploadID, http.StatusOK, 0},
+		{upload.UploadID, http.StatusOK, 1},
+		{upload.UploadID, http.StatusNotFound, 0},
+		{"not-an-id", http.StatusBadRequest, 0},
+	} {
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest(http.MethodDelete, "/uploads/"+tt.uploadID, nil)
+		router.ServeHTTP(w, req)
+		var response DeleteUploadResponse
+		json.Unmarshal(w.Body.Bytes(), &response)
+		if w.Code != tt.status || response.GamesDeleted != tt.deleted {
+			t.Errorf("Expected deleting upload %s to give %d with %d game(s) deleted, got %d: %s", tt.uploadID, tt.status, tt.deleted, w.Code, w.Body.String())
+		}
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/games/"+upload.GameIDs[0], nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected the game to be rolled back with its upload, got %d", w.Code)
+	}
+}